@@ -22,6 +22,10 @@ const (
 	FlagImageModel           = "model"
 	FlagImageQuality         = "quality"
 	FlagImageStyle           = "style"
+	FlagImageBackground      = "background"
+	FlagImageFormat          = "image-format"
+	FlagImageCompression     = "compression"
+	FlagImageModeration      = "moderation"
 	FlagOutputPrefix         = "output-prefix"
 	FlagDetail               = "detail"
 	FlagVoice                = "voice"
@@ -30,6 +34,49 @@ const (
 	FlagLanguage             = "language"
 	FlagEmbeddingModel       = "model"
 	FlagDimensions           = "dimensions"
+	FlagPersona              = "persona"
+	FlagPersonasFile         = "personas-file"
+	FlagRender               = "render"
+	FlagNoRender             = "no-render"
+	FlagExtractCode          = "extract-code"
+	FlagOnlyCode             = "only-code"
+	FlagAttach               = "attach"
+	FlagPages                = "pages"
+	FlagShell                = "shell"
+	FlagExplain              = "explain"
+	FlagTemplate             = "template"
+	FlagMaxDiffSize          = "max-diff-size"
+	FlagInstallHook          = "install-hook"
+	FlagHook                 = "hook"
+	FlagImageInput           = "input"
+	FlagMask                 = "mask"
+	FlagMaskRect             = "mask-rect"
+	FlagImageOutputFormat    = "output-format"
+	FlagJpegQuality          = "jpeg-quality"
+	FlagJSON                 = "json"
+	FlagImagePreview         = "preview"
+	FlagPromptsFile          = "prompts"
+	FlagConcurrency          = "concurrency"
+	FlagRetries              = "retries"
+	FlagSpeechFormat         = "format"
+	FlagOutput               = "output"
+	FlagResume               = "resume"
+	FlagInstructions         = "instructions"
+	FlagOutputDir            = "output-dir"
+	FlagSampleText           = "text"
+	FlagSpeak                = "speak"
+	FlagPlay                 = "play"
+	FlagSpeechModel          = "speech-model"
+	FlagTranscriptionModel   = "transcription-model"
+	FlagTranscriptionFormat  = "transcription-format"
+	FlagTimestamps           = "timestamps"
+	FlagMaxLineLength        = "max-line-length"
+	FlagSplitCommand         = "split-command"
+	FlagSummarize            = "summarize"
+	FlagSummaryModel         = "summary-model"
+	FlagSummaryTemplate      = "summary-template"
+	FlagInputDir             = "dir"
+	FlagGlob                 = "glob"
 )
 
 const (
@@ -44,12 +91,28 @@ const (
 	defaultImageQuality        = openai.CreateImageQualityHigh
 	defaultImageStyle          = openai.CreateImageStyleVivid
 	defaultImageSize           = openai.CreateImageSize1024x1024
+	defaultImageBackground     = ImageBackgroundAuto
+	defaultImageFormat         = openai.CreateImageOutputFormatPNG
+	defaultImageCompression    = 100
+	defaultImageModeration     = ImageModerationAuto
+	defaultImageOutputFormat   = ImageOutputFormatPNG
+	defaultJpegQuality         = 90
+	defaultImagePreview        = ImagePreviewAuto
+	defaultConcurrency         = 3
+	defaultRetries             = 2
 	defaultDetail              = string(openai.ImageURLDetailAuto)
 	defaultSpeed               = 1.0
 	defaultVoice               = string(openai.VoiceAlloy)
 	defaultSpeechModel         = string(openai.TTSModel1)
+	defaultSpeechFormat        = string(openai.SpeechResponseFormatMp3)
+	defaultTranscriptionModel  = openai.Whisper1
+	defaultTranscriptionFormat = string(openai.AudioResponseFormatText)
+	defaultSummaryTemplate     = "meeting"
+	defaultVoicesDir           = "voices"
+	defaultVoiceSampleText     = "The quick brown fox jumps over the lazy dog, then naps in the afternoon sun."
 	defaultEmbeddingModel      = string(openai.SmallEmbedding3)
 	defaultDimensions          = 0
+	defaultMaxDiffSize         = 48000
 )
 
 // AddConfigFileFlag initialises the ConfigFile flag.
@@ -80,7 +143,47 @@ func AddDetailFlag(str *string, flags *pflag.FlagSet) {
 }
 
 func AddVoiceFlag(str *string, flags *pflag.FlagSet) {
-	flags.StringVar(str, FlagVoice, defaultVoice, "control the voice used. Must be one of alloy, echo, fable, onyx, nova, or shimmer")
+	flags.StringVar(str, FlagVoice, defaultVoice, "control the voice used. Must be one of alloy, ash, coral, echo, fable, nova, onyx, sage, or shimmer, or for gpt-4o-mini-tts also ballad, verse, marin, or cedar")
+}
+
+func AddSpeechFormatFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagSpeechFormat, defaultSpeechFormat, "Audio format. Must be one of mp3, opus, aac, flac, wav, or pcm")
+}
+
+func AddOutputFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagOutput, "", "File to write the audio to, instead of a name from the output prefix, or - to write to stdout")
+}
+
+func AddInstructionsFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagInstructions, "", "Instructions for the tone, accent, and pacing of the voice, for gpt-4o-mini-tts")
+}
+
+func AddOutputDirFlag(str *string, defaultDir string, usage string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagOutputDir, defaultDir, usage)
+}
+
+func AddSampleTextFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagSampleText, defaultVoiceSampleText, "Sentence read by each voice")
+}
+
+func AddSpeakFlag(b *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(b, FlagSpeak, false, "Read each response aloud, saving the audio next to the session")
+}
+
+func AddPlayFlag(b *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(b, FlagPlay, false, "Play the audio of each response, with --speak")
+}
+
+func AddSpeakModelFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagSpeechModel, defaultSpeechModel, "Text to Speech Model used with --speak")
+}
+
+func AddSpeakSpeedFlag(f *float64, flags *pflag.FlagSet) {
+	flags.Float64Var(f, FlagSpeed, defaultSpeed, "control speed of audio with --speak. Must be between 0.25 and 4.0")
+}
+
+func AddResumeFlag(b *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(b, FlagResume, false, "Reuse the chunks of long text already created by a failed run with the same text")
 }
 
 func AddSpeedFlag(f *float64, flags *pflag.FlagSet) {
@@ -88,7 +191,7 @@ func AddSpeedFlag(f *float64, flags *pflag.FlagSet) {
 }
 
 func AddInputFileFlag(str *[]string, flags *pflag.FlagSet) {
-	flags.StringArrayVarP(str, FlagInputFile, "f", nil, "Input image files, maybe specified more than once")
+	flags.StringArrayVarP(str, FlagInputFile, "f", nil, "Input files, maybe specified more than once")
 }
 
 func AddRoleFlag(str *string, flags *pflag.FlagSet) {
@@ -111,6 +214,62 @@ func AddInitialSystemMessageFlag(str *string, flags *pflag.FlagSet) {
 	flags.StringVar(str, FlagInitialSystemMessage, defaultSystemMessage, "Initial System message sent to ChatGPT")
 }
 
+func AddPersonaFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagPersona, "", "Named persona to use, providing the system message, model, and parameters")
+}
+
+func AddPersonasFileFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagPersonasFile, "", "Personas file (default ./.chatgpt-cli-personas.yaml then $HOME/.chatgpt-cli-personas.yaml)")
+}
+
+func AddRenderFlag(b *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(b, FlagRender, false, "Render Markdown responses, even when not in an interactive session")
+}
+
+func AddNoRenderFlag(b *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(b, FlagNoRender, false, "Print raw responses, without rendering Markdown")
+}
+
+func AddExtractCodeFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagExtractCode, "", "Directory to save fenced code blocks from each response to")
+}
+
+func AddOnlyCodeFlag(b *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(b, FlagOnlyCode, false, "Print only the fenced code blocks from each response")
+}
+
+func AddAttachFlag(str *[]string, flags *pflag.FlagSet) {
+	flags.StringArrayVarP(str, FlagAttach, "a", nil, "Image or PDF files, or image https:// URLs, to attach to the first message, maybe specified more than once. PDF pages are sent as their text and embedded images; pages are not rasterized, so vector drawings such as charts are not sent")
+}
+
+func AddPagesFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagPages, "", "Pages of PDF files to send, such as 3 or 1-5, all pages if not set. Each page is sent as its text and embedded images; pages are not rasterized, so vector drawings such as charts are not sent")
+}
+
+func AddShellFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagShell, "", "Shell to generate and run commands for (default $SHELL)")
+}
+
+func AddExplainFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagExplain, "", "Explain an existing command line, instead of generating one")
+}
+
+func AddTemplateFileFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagTemplate, "", "File containing instructions to use in place of the built-in prompt")
+}
+
+func AddMaxDiffSizeFlag(i *int, flags *pflag.FlagSet) {
+	flags.IntVar(i, FlagMaxDiffSize, defaultMaxDiffSize, "Maximum characters of diff sent in one request, larger diffs are split by file")
+}
+
+func AddInstallHookFlag(b *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(b, FlagInstallHook, false, "Install as the prepare-commit-msg hook of the current repository")
+}
+
+func AddHookFlag(b *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(b, FlagHook, false, "Run as the prepare-commit-msg hook, with the hook's arguments")
+}
+
 func AddTemperatureFlag(f *float32, flags *pflag.FlagSet) {
 	flags.Float32Var(f, FlagTemperature, defaultTemperature, "Temperature, between 0 and 2. Higher values make the output more random")
 }
@@ -124,7 +283,7 @@ func AddTopPFlag(f *float32, flags *pflag.FlagSet) {
 }
 
 func AddNumberImagesFlag(n *int, flags *pflag.FlagSet) {
-	flags.IntVarP(n, FlagNumberImages, "n", defaultNumberImages, "Number of images to generate, between 1 and 10, for DALL-E 2")
+	flags.IntVarP(n, FlagNumberImages, "n", defaultNumberImages, "Number of images to generate, between 1 and 10, for GPT-Image-1 and DALL-E 2")
 }
 
 func AddImageModelFlag(str *string, flags *pflag.FlagSet) {
@@ -135,6 +294,22 @@ func AddImageStyleFlag(str *string, flags *pflag.FlagSet) {
 	flags.StringVar(str, FlagImageStyle, defaultImageStyle, "Style to use for image generation. Must be one of 'vivid' or 'natural' (for DALL-E 3 only)")
 }
 
+func AddImageBackgroundFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagImageBackground, defaultImageBackground, "Background of the generated images. Must be one of 'auto', 'transparent', or 'opaque' (for GPT-Image-1 only)")
+}
+
+func AddImageFormatFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagImageFormat, defaultImageFormat, "Format the images are generated in. Must be one of 'png', 'jpeg', or 'webp' (for GPT-Image-1 only)")
+}
+
+func AddImageCompressionFlag(i *int, flags *pflag.FlagSet) {
+	flags.IntVar(i, FlagImageCompression, defaultImageCompression, "Compression level of jpeg or webp generated images, between 0 and 100 (for GPT-Image-1 only)")
+}
+
+func AddImageModerationFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagImageModeration, defaultImageModeration, "Content moderation level of generated images. Must be one of 'auto' or 'low' (for GPT-Image-1 only)")
+}
+
 func AddImageQualityFlag(str *string, flags *pflag.FlagSet) {
 	flags.StringVar(str, FlagImageQuality, defaultImageQuality, "Quality to use for image generation. Must be one of 'standard' or 'hd' (for GPT-Image-1 and DALL-E 3)")
 }
@@ -143,12 +318,100 @@ func AddImageSizeFlag(str *string, flags *pflag.FlagSet) {
 	flags.StringVarP(str, FlagImageSize, "s", defaultImageSize, "Size of the generated images. Must be one of: 256x256, 512x512, or 1024x1024 for DALL-E 2; 1024x1024, 1792x1024, or 1024x1792 for DALL-E 3; or 1024x1024, 1024x1536, or 1536x1024 for GPT-Image-1")
 }
 
+func AddImageOutputFormatFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagImageOutputFormat, defaultImageOutputFormat, "Format images are saved in. Must be one of 'png', 'jpeg', 'webp-raw' (WebP as received), or 'keep' (the format received)")
+}
+
+func AddJpegQualityFlag(i *int, flags *pflag.FlagSet) {
+	flags.IntVar(i, FlagJpegQuality, defaultJpegQuality, "Quality used when converting images to JPEG, between 1 and 100")
+}
+
+func AddJSONFlag(b *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(b, FlagJSON, false, "Print the saved images, revised prompts, sizes, and usage as JSON")
+}
+
+func AddImagePreviewFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagImagePreview, defaultImagePreview, "How saved images are shown. Must be one of 'auto', 'inline' (in the terminal), 'open' (with the default application), or 'none'")
+}
+
+func AddPromptsFileFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagPromptsFile, "", "File of prompts to generate images for, one per line, or JSON lines with prompt, size, quality, and style")
+}
+
+func AddConcurrencyFlag(i *int, flags *pflag.FlagSet) {
+	flags.IntVar(i, FlagConcurrency, defaultConcurrency, "Number of requests sent at the same time")
+}
+
+func AddRetriesFlag(i *int, flags *pflag.FlagSet) {
+	flags.IntVar(i, FlagRetries, defaultRetries, "Number of times a failed request is retried")
+}
+
 func AddOutputPrefixFlag(str *string, defaultName string, flags *pflag.FlagSet) {
 	flags.StringVarP(str, FlagOutputPrefix, "o", defaultName, "Prefix used for the output file names")
 }
 
+func AddImageInputFlag(str *[]string, flags *pflag.FlagSet) {
+	flags.StringArrayVarP(str, FlagImageInput, "i", nil, "Input image files to edit, maybe specified more than once for GPT-Image-1")
+}
+
+func AddMaskFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagMask, "", "PNG mask, the same size as the first input image, where fully transparent areas are edited")
+}
+
+func AddMaskRectFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagMaskRect, "", "Generate a mask that edits only the rectangle x,y,w,h of the first input image, in pixels")
+}
+
+func AddTranscriptionModelFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVarP(str, FlagTranscriptionModel, "m", defaultTranscriptionModel, "Transcription Model. Must be one of whisper-1, gpt-4o-transcribe, or gpt-4o-mini-transcribe")
+}
+
+func AddTranslationModelFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVarP(str, FlagTranscriptionModel, "m", defaultTranscriptionModel, "Translation Model. Must be whisper-1")
+}
+
+func AddTranscriptionFormatFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagTranscriptionFormat, defaultTranscriptionFormat, "Output format. Must be one of text, json, verbose_json, srt, or vtt; only text and json for the gpt-4o models")
+}
+
+func AddTimestampsFlag(str *[]string, flags *pflag.FlagSet) {
+	flags.StringSliceVar(str, FlagTimestamps, nil, "Timestamp granularities to include with the verbose_json format, segment, word, or both")
+}
+
+func AddMaxLineLengthFlag(i *int, flags *pflag.FlagSet) {
+	flags.IntVar(i, FlagMaxLineLength, 0, "Re-wrap srt or vtt subtitle cues to lines of at most this many characters (0 to keep them as returned)")
+}
+
+func AddSplitCommandFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagSplitCommand, "", "Shell command that splits recordings of 24 MB or more, other than WAV, reading $SPLIT_INPUT and writing chunks to $SPLIT_DIR")
+}
+
+func AddSummarizeFlag(b *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(b, FlagSummarize, false, "Send each transcript to ChatGPT for notes with a summary, decisions, and action items, saved with the transcript to <name>.session.json")
+}
+
+func AddSummaryModelFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagSummaryModel, defaultModel, "ChatGPT Model used with --summarize")
+}
+
+func AddSummaryTemplateFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagSummaryTemplate, defaultSummaryTemplate, "Notes template used with --summarize, one of call, meeting, or standup, or a file containing instructions")
+}
+
+func AddInputDirFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagInputDir, "", "Directory of recordings to transcribe, with the --file recordings")
+}
+
+func AddGlobFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(str, FlagGlob, "", "Pattern of the names of the recordings in --dir, such as *.m4a, by default every audio file")
+}
+
+func AddTranscriptionJSONFlag(b *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(b, FlagJSON, false, "Print the source file, status, output file, and text of each transcript as JSON")
+}
+
 func AddLanguageFlag(str *string, flags *pflag.FlagSet) {
-	flags.StringVarP(str, FlagLanguage, "l", "", "language of the input audio")
+	flags.StringVarP(str, FlagLanguage, "l", "", "language of the input audio, as an ISO-639-1 code, such as en")
 }
 
 func AddEmbeddingModelFlag(str *string, flags *pflag.FlagSet) {
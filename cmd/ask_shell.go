@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/duanemay/chatgpt-cli/pkg/markdown"
+	"github.com/pterm/pterm"
+	"github.com/sashabaranov/go-openai"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	shellActionRun    = "run"
+	shellActionEdit   = "edit"
+	shellActionCopy   = "copy to file"
+	shellActionCancel = "cancel"
+
+	// maxShellErrorOutput limits how much of a failed command's output is sent back to ChatGPT
+	maxShellErrorOutput = 4000
+)
+
+func NewAskShellCmd(rootFlags *RootFlags) *cobra.Command {
+	askShellFlags := NewAskShellFlags()
+	chatContext := NewChatContext()
+	var cmd = &cobra.Command{
+		Use:     "ask-shell [request]",
+		Aliases: []string{"sh"},
+		Short:   "Turn a request into a shell command, explain it, and optionally run it",
+		Long:    "Turn a natural language request into a shell command for your OS, shell, and directory, explain it, and optionally run it",
+		RunE:    askShellCmdRunner(rootFlags, askShellFlags, chatContext),
+	}
+	setChatContext(cmd, chatContext)
+
+	AddModelFlag(&askShellFlags.model, cmd.PersistentFlags())
+	AddShellFlag(&askShellFlags.shell, cmd.PersistentFlags())
+	AddExplainFlag(&askShellFlags.explain, cmd.PersistentFlags())
+	_ = cmd.MarkPersistentFlagRequired(FlagApiKey)
+
+	return cmd
+}
+
+func askShellCmdRunner(rootFlags *RootFlags, askShellFlags *AskShellFlags, chatContext *ChatContext) func(cmd *cobra.Command, args []string) error {
+	return func(_ *cobra.Command, args []string) error {
+		log.Debugf("askShellCmd called")
+		err := askShellFlags.ValidateFlags()
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+
+		chatContext.InteractiveSession = detectTerminal()
+		chatContext.RenderMarkdown = detectRenderMarkdown(false, false, chatContext)
+		if chatContext.InteractiveSession {
+			printAskShellBanner(askShellFlags)
+		}
+		client, err := setupOpenAIClient(rootFlags.apikey)
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+
+		chatFlags := ChatFlagsFromAskShellFlags(askShellFlags)
+		chatCompletionRequest := loadOrCreateChatCompletionRequest(chatFlags, chatContext)
+
+		if askShellFlags.explain != "" {
+			chatCompletionRequest.Messages = append(chatCompletionRequest.Messages, openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: explainShellSystemMessage(askShellFlags.shell),
+			})
+			if err := sendChatMessages(chatFlags, chatContext, chatCompletionRequest, client, askShellFlags.explain); err != nil {
+				log.WithError(err).Fatal()
+			}
+			return nil
+		}
+
+		chatCompletionRequest.Messages = append(chatCompletionRequest.Messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: askShellSystemMessage(askShellFlags.shell),
+		})
+
+		chatRequestString := strings.Join(args, " ")
+		if chatRequestString == "" {
+			reader := bufio.NewReader(os.Stdin)
+			chatRequestString = readUserInput(chatContext, reader, "Describe the command you need")
+		}
+		if len(chatRequestString) == 0 {
+			ErrorFmt.Printf("No Request to Send, exiting...\n")
+			return nil
+		}
+
+		// when piped, print just the command, so it can be used by a script
+		if !chatContext.InteractiveSession {
+			chatContext.SuppressResponse = true
+			if err := sendChatMessages(chatFlags, chatContext, chatCompletionRequest, client, chatRequestString); err != nil {
+				log.WithError(err).Fatal()
+			}
+			fmt.Printf("%s\n", shellCommandFromResponse(lastAssistantMessage(chatCompletionRequest)))
+			return nil
+		}
+
+		for {
+			if err := sendChatMessages(chatFlags, chatContext, chatCompletionRequest, client, chatRequestString); err != nil {
+				log.WithError(err).Fatal()
+			}
+			command := shellCommandFromResponse(lastAssistantMessage(chatCompletionRequest))
+			if command == "" {
+				ErrorFmt.Printf("No command found in the response, exiting...\n")
+				return nil
+			}
+
+			command, exitCode, output, ran := chooseShellAction(askShellFlags, command)
+			if !ran || exitCode == 0 {
+				return nil
+			}
+
+			askForFix, _ := pterm.DefaultInteractiveConfirm.
+				WithDefaultText(fmt.Sprintf("Command failed with exit status %d, ask ChatGPT for a fix?", exitCode)).
+				WithDefaultValue(true).Show()
+			if !askForFix {
+				return nil
+			}
+			chatRequestString = shellFixRequest(command, exitCode, output)
+		}
+	}
+}
+
+func printAskShellBanner(f *AskShellFlags) {
+	TitleFmt.Printf("ChatGPT CLI v%s\n", version)
+	fmt.Printf("model: %s, shell: %s\n", f.model, f.shell)
+	fmt.Printf("- Press TAB after entering a message to send.\n")
+	fmt.Printf("- Press TAB or CTRL+C with a blank message to terminate the session without sending.\n")
+}
+
+// askShellSystemMessage describes the user's environment, and the expected response format
+func askShellSystemMessage(shell string) string {
+	cwd, _ := os.Getwd()
+	return fmt.Sprintf("You are an expert at the command line. The user is running the %s shell on %s/%s, "+
+		"in the directory %s. Reply with a single command line that does what the user asks, in one fenced "+
+		"code block with the language %s, followed by a brief explanation of what the command and each of its "+
+		"options do. Do not include any other code blocks. Prefer safe, non-destructive commands.",
+		shell, runtime.GOOS, runtime.GOARCH, cwd, shellName(shell))
+}
+
+// explainShellSystemMessage asks for an explanation of a command line
+func explainShellSystemMessage(shell string) string {
+	return fmt.Sprintf("You are an expert at the command line. The user is running the %s shell on %s/%s. "+
+		"Explain what the command line you are given does, breaking it down into each command, option, "+
+		"and argument. Point out anything that is destructive or dangerous.",
+		shell, runtime.GOOS, runtime.GOARCH)
+}
+
+// shellFixRequest asks ChatGPT to correct a command that failed
+func shellFixRequest(command string, exitCode int, output string) string {
+	if len(output) > maxShellErrorOutput {
+		start := len(output) - maxShellErrorOutput
+		for start < len(output) && !utf8.RuneStart(output[start]) {
+			start++
+		}
+		output = output[start:]
+	}
+	return fmt.Sprintf("The command `%s` failed with exit status %d, and this output:\n```\n%s\n```\nSuggest a corrected command.",
+		command, exitCode, output)
+}
+
+// shellCommandFromResponse returns the command from the first code block in the response
+func shellCommandFromResponse(content string) string {
+	blocks := markdown.ExtractCodeBlocks(content)
+	if len(blocks) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(blocks[0].Code)
+}
+
+// chooseShellAction offers to run, edit, or copy the command to a file.
+// Returns the command as edited, its exit code and output, and if it was run.
+func chooseShellAction(f *AskShellFlags, command string) (string, int, string, bool) {
+	for {
+		TitleFmt.Printf("\n%s\n", command)
+		action, _ := pterm.DefaultInteractiveSelect.
+			WithDefaultText("What would you like to do?").
+			WithOptions([]string{shellActionRun, shellActionEdit, shellActionCopy, shellActionCancel}).
+			Show()
+
+		switch action {
+		case shellActionRun:
+			exitCode, output, err := runShellCommand(f.shell, command, os.Stdout, os.Stderr)
+			if err != nil {
+				ErrorFmt.Printf("%v\n", err)
+				return command, 0, "", false
+			}
+			return command, exitCode, output, true
+		case shellActionEdit:
+			edited, _ := pterm.DefaultInteractiveTextInput.WithDefaultText("Edit command").WithDefaultValue(command).Show()
+			if strings.TrimSpace(edited) != "" {
+				command = strings.TrimSpace(edited)
+			}
+		case shellActionCopy:
+			fileName, _ := pterm.DefaultInteractiveTextInput.WithDefaultText("File name").WithDefaultValue("command.sh").Show()
+			if err := os.WriteFile(fileName, []byte(command+"\n"), 0755); err != nil {
+				ErrorFmt.Printf("File write error: %v\n", err)
+				continue
+			}
+			fmt.Printf("%s\n", fileName)
+			return command, 0, "", false
+		default:
+			return command, 0, "", false
+		}
+	}
+}
+
+// runShellCommand runs the command with the given shell, reading from the terminal and showing its output on stdout
+// and stderr. Returns the exit code, and the combined output so a failure can be sent back to ChatGPT.
+func runShellCommand(shell string, command string, stdout io.Writer, stderr io.Writer) (int, string, error) {
+	var output bytes.Buffer
+	c := exec.Command(shell, shellCommandArgs(shell, command)...)
+	c.Stdin = os.Stdin
+	c.Stdout = io.MultiWriter(stdout, &output)
+	c.Stderr = io.MultiWriter(stderr, &output)
+
+	err := c.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), output.String(), nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("unable to run command: %w", err)
+	}
+	return 0, output.String(), nil
+}
+
+// shellCommandArgs returns the arguments that make the shell run a single command line
+func shellCommandArgs(shell string, command string) []string {
+	switch shellName(shell) {
+	case "cmd":
+		return []string{"/C", command}
+	case "powershell", "pwsh":
+		return []string{"-NoProfile", "-Command", command}
+	default:
+		return []string{"-c", command}
+	}
+}
+
+// shellName returns the name of the shell, without a directory or extension, for either path separator
+func shellName(shell string) string {
+	name := shell[strings.LastIndexAny(shell, `/\`)+1:]
+	return strings.TrimSuffix(strings.ToLower(name), ".exe")
+}
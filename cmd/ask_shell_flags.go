@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"os"
+	"runtime"
+)
+
+type AskShellFlags struct {
+	model   string
+	shell   string
+	explain string
+}
+
+func NewAskShellFlags() *AskShellFlags {
+	return &AskShellFlags{}
+}
+
+func (f *AskShellFlags) ValidateFlags() error {
+	if f.shell == "" {
+		f.shell = defaultShell()
+	}
+	return nil
+}
+
+// defaultShell returns the user's shell from $SHELL, or the platform default
+func defaultShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	if runtime.GOOS == "windows" {
+		return "cmd"
+	}
+	return "/bin/sh"
+}
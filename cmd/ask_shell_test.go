@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ask Shell Command", func() {
+	It("should find command, and alias", func() {
+		var thisCmd *cobra.Command
+		Ω(NewRootCmd().Commands()).To(ContainElement(HaveField("Use", "ask-shell [request]"), &thisCmd))
+		Ω(thisCmd.Name()).To(Equal("ask-shell"))
+		Ω(thisCmd.Aliases).To(ContainElement("sh"))
+	})
+
+	It("should take the command from the first code block", func() {
+		response := "```bash\nfind . -size +100M\n```\nThis finds files larger than 100MB.\n"
+		Ω(shellCommandFromResponse(response)).To(Equal("find . -size +100M"))
+		Ω(shellCommandFromResponse("no code here")).To(BeEmpty())
+	})
+
+	It("should build arguments for the shell", func() {
+		Ω(shellCommandArgs("/bin/zsh", "ls")).To(Equal([]string{"-c", "ls"}))
+		Ω(shellCommandArgs(`C:\Windows\System32\cmd.exe`, "dir")).To(Equal([]string{"/C", "dir"}))
+		Ω(shellCommandArgs("pwsh", "Get-ChildItem")).To(Equal([]string{"-NoProfile", "-Command", "Get-ChildItem"}))
+	})
+
+	It("should capture the exit status and output", func() {
+		var stdout, stderr bytes.Buffer
+		exitCode, output, err := runShellCommand("/bin/sh", "echo done; echo oops >&2; exit 3", &stdout, &stderr)
+
+		Ω(err).ToNot(HaveOccurred())
+		Ω(exitCode).To(Equal(3))
+		Ω(output).To(ContainSubstring("done"))
+		Ω(output).To(ContainSubstring("oops"))
+		Ω(stdout.String()).To(Equal("done\n"))
+		Ω(stderr.String()).To(Equal("oops\n"))
+	})
+
+	It("should keep the end of long error output", func() {
+		long := make([]byte, maxShellErrorOutput*2)
+		for i := range long {
+			long[i] = 'x'
+		}
+		request := shellFixRequest("make", 2, string(long)+"the end")
+		Ω(request).To(ContainSubstring("the end"))
+		Ω(len(request)).To(BeNumerically("<", maxShellErrorOutput+200))
+	})
+
+	It("should not split a character when keeping the end of long error output", func() {
+		request := shellFixRequest("make", 2, strings.Repeat("€", maxShellErrorOutput/2))
+		Ω(utf8.ValidString(request)).To(BeTrue())
+	})
+})
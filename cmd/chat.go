@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"bufio"
-	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/duanemay/chatgpt-cli/pkg/markdown"
 	"github.com/sashabaranov/go-openai"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -30,15 +34,39 @@ func NewChatCmd(rootFlags *RootFlags) *cobra.Command {
 	AddTemperatureFlag(&chatFlags.temperature, cmd.PersistentFlags())
 	AddMaxCompletionTokensFlag(&chatFlags.maxCompletionTokens, cmd.PersistentFlags())
 	AddTopPFlag(&chatFlags.topP, cmd.PersistentFlags())
+	AddPersonaFlag(&chatFlags.persona, cmd.PersistentFlags())
+	AddPersonasFileFlag(&chatFlags.personasFile, cmd.PersistentFlags())
+	AddRenderFlag(&chatFlags.render, cmd.PersistentFlags())
+	AddNoRenderFlag(&chatFlags.noRender, cmd.PersistentFlags())
+	AddExtractCodeFlag(&chatFlags.extractCodeDir, cmd.PersistentFlags())
+	AddOnlyCodeFlag(&chatFlags.onlyCode, cmd.PersistentFlags())
+	AddAttachFlag(&chatFlags.attachFiles, cmd.PersistentFlags())
+	AddDetailFlag(&chatFlags.detailStr, cmd.PersistentFlags())
+	AddPagesFlag(&chatFlags.pages, cmd.PersistentFlags())
+	AddSpeakFlag(&chatFlags.speak, cmd.PersistentFlags())
+	AddPlayFlag(&chatFlags.play, cmd.PersistentFlags())
+	AddSpeakModelFlag(&chatFlags.speech.ModelStr, cmd.PersistentFlags())
+	AddVoiceFlag(&chatFlags.speech.VoiceStr, cmd.PersistentFlags())
+	AddSpeakSpeedFlag(&chatFlags.speech.Speed, cmd.PersistentFlags())
+	AddInstructionsFlag(&chatFlags.speech.Instructions, cmd.PersistentFlags())
 	_ = cmd.MarkPersistentFlagRequired(FlagApiKey)
 
 	return cmd
 }
 
 func chatCmdRun(rootFlags *RootFlags, chatFlags *ChatFlags, chatContext *ChatContext) func(cmd *cobra.Command, args []string) error {
-	return func(_ *cobra.Command, _ []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
 		log.Debugf("chatCmd called")
+		if err := chatFlags.ValidateFlags(); err != nil {
+			log.WithError(err).Fatal()
+		}
+		if err := applyPersona(cmd, chatFlags); err != nil {
+			log.WithError(err).Fatal()
+		}
+
 		chatContext.InteractiveSession = detectTerminal()
+		chatContext.RenderMarkdown = detectRenderMarkdown(chatFlags.render, chatFlags.noRender, chatContext)
+		chatContext.SuppressResponse = chatFlags.onlyCode
 		if chatContext.InteractiveSession {
 			printBanner(chatFlags)
 		}
@@ -48,6 +76,9 @@ func chatCmdRun(rootFlags *RootFlags, chatFlags *ChatFlags, chatContext *ChatCon
 		}
 
 		chatCompletionRequest := loadOrCreateChatCompletionRequest(chatFlags, chatContext)
+		chatFlags.speech.OutputPrefix = speechOutputPrefix(chatFlags)
+		chatFlags.speech.CurrentImageCount = nextFileNumber(chatFlags.speech.OutputPrefix)
+		recordPersona(chatFlags, chatCompletionRequest)
 		if chatFlags.initialSystemMessage != "" {
 			chatCompletionRequest.Messages = append(chatCompletionRequest.Messages, openai.ChatCompletionMessage{
 				Role:    "system",
@@ -55,60 +86,160 @@ func chatCmdRun(rootFlags *RootFlags, chatFlags *ChatFlags, chatContext *ChatCon
 			})
 		}
 
-		reader := bufio.NewReader(os.Stdin)
-		for {
-			chatRequestString := readUserInput(chatContext, reader, "Enter Message")
-			if len(chatRequestString) == 0 {
-				ErrorFmt.Printf("No Message to Send, exiting...\n")
-				return nil
-			}
+		runChatLoop(chatFlags, chatContext, chatCompletionRequest, client)
+		return nil
+	}
+}
 
-			if err := sendChatMessages(chatFlags, chatContext, chatCompletionRequest, client, chatRequestString); err != nil {
-				log.WithError(err).Fatal()
+// runChatLoop reads messages and sends them, until a blank message, or after one message when not interactive
+func runChatLoop(chatFlags *ChatFlags, chatContext *ChatContext, chatCompletionRequest *openai.ChatCompletionRequest, client *openai.Client) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		chatRequestString := readUserInput(chatContext, reader, "Enter Message")
+		if len(chatRequestString) == 0 {
+			ErrorFmt.Printf("No Message to Send, exiting...\n")
+			return
+		}
+
+		if chatContext.InteractiveSession {
+			if handled, err := runSlashCommand(chatFlags, chatContext, chatCompletionRequest, chatRequestString); handled {
+				if err != nil {
+					ErrorFmt.Printf("%v\n", err)
+				}
+				continue
 			}
+		}
 
-			if shouldWriteSession(chatFlags) {
-				writeSessionFile(chatFlags, chatCompletionRequest)
+		if err := sendChatMessages(chatFlags, chatContext, chatCompletionRequest, client, chatRequestString); err != nil {
+			log.WithError(err).Fatal()
+		}
+
+		response := lastAssistantMessage(chatCompletionRequest)
+		if chatFlags.onlyCode {
+			printCodeBlocks(response)
+		}
+		if chatFlags.extractCodeDir != "" {
+			if err := saveCodeBlocks(chatContext, chatFlags.extractCodeDir, response); err != nil {
+				ErrorFmt.Printf("%v\n", err)
 			}
+		}
+		if chatFlags.speak {
+			if err := speakResponse(chatFlags, client, response); err != nil {
+				ErrorFmt.Printf("%v\n", err)
+			}
+		}
 
-			if !chatContext.InteractiveSession {
-				break
+		if shouldWriteSession(chatFlags) {
+			if err := writeSessionFile(chatFlags, chatCompletionRequest); err != nil {
+				log.WithError(err).Fatal()
 			}
 		}
-		return nil
+
+		if !chatContext.InteractiveSession {
+			return
+		}
 	}
 }
 
 func printBanner(f *ChatFlags) {
 	TitleFmt.Printf("ChatGPT CLI v%s\n", version)
+	if f.persona != "" {
+		fmt.Printf("persona: %s\n", f.persona)
+	}
 	fmt.Printf("model: %s, role: %s, temp: %0.1f, maxtok: %d, topp: %0.1f\n", f.model, f.role, f.temperature, f.maxCompletionTokens, f.topP)
+	if f.speak {
+		fmt.Printf("speaking with model: %s, voice: %s, speed: %0.2f\n", f.speech.Model, f.speech.Voice, f.speech.Speed)
+	}
 	fmt.Printf("- Press TAB after entering a message to send.\n")
 	fmt.Printf("- Press TAB or CTRL+C with a blank message to terminate the session without sending.\n")
+	fmt.Printf("- Enter /save-code [dir] to save the code blocks from the last response.\n")
+	fmt.Printf("- Enter /attach <path> to attach an image or PDF to the next message.\n")
+}
+
+// speechOutputPrefix names the audio of the responses after the session file, so it is saved next to the session
+func speechOutputPrefix(f *ChatFlags) string {
+	if f.sessionFile == "" {
+		return "chat-" + time.Now().UTC().Format(time.RFC3339)
+	}
+	return strings.TrimSuffix(f.sessionFile, filepath.Ext(f.sessionFile))
+}
+
+// nextFileNumber continues the numbering of the files saved with the prefix, such as the audio of a resumed session,
+// so the files saved by earlier responses or runs are not overwritten
+func nextFileNumber(prefix string) int {
+	entries, err := os.ReadDir(filepath.Dir(prefix))
+	if err != nil {
+		return 1
+	}
+	base := filepath.Base(prefix) + "-"
+	next := 1
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, base), filepath.Ext(name)))
+		if err == nil && number >= next {
+			next = number + 1
+		}
+	}
+	return next
+}
+
+// speakResponse reads a response aloud, without its Markdown formatting and code blocks
+func speakResponse(f *ChatFlags, client *openai.Client, response string) error {
+	text := markdown.SpeakableText(response)
+	if text == "" {
+		return nil
+	}
+	return sendSpeechMessages(f.speech, client, text)
 }
 
 // sendMessages sends messages to ChatGPT and prints the response
 func sendChatMessages(f *ChatFlags, chatContext *ChatContext, chatCompletionRequest *openai.ChatCompletionRequest, client *openai.Client, chatRequestString string) error {
+	userMessage, err := newUserMessage(f, chatRequestString)
+	if err != nil {
+		return err
+	}
+
 	mySpinner := newSpinner()
 	successSpinner, _ := mySpinner.Start("Sending to ChatGPT, please wait...")
 
-	chatCompletionRequest.Messages = append(chatCompletionRequest.Messages, openai.ChatCompletionMessage{
-		Role:    f.role,
-		Content: chatRequestString,
-	})
-	resp, err := client.CreateChatCompletion(context.Background(), *chatCompletionRequest)
+	chatCompletionRequest.Messages = append(addToolResults(chatCompletionRequest.Messages), userMessage)
+	message, err := streamChatCompletion(chatContext, client, chatCompletionRequest, successSpinner)
 	if err != nil {
-		successSpinner.Fail(err.Error())
 		return err
 	}
-	successSpinner.Success()
+	chatCompletionRequest.Messages = addToolResults(append(chatCompletionRequest.Messages, message))
 
-	for _, choice := range resp.Choices {
-		if chatContext.InteractiveSession {
-			AiFmt.Printf("\nChatGPT response:\n")
+	return nil
+}
+
+// toolNotRun is the result given for each tool call, as tools are passed to the model but never run
+const toolNotRun = "This tool is not available, it was not run."
+
+// addToolResults answers the tool calls of each response that has not been answered, such as in a session saved
+// before they were answered, as the API rejects a request with a tool call that has no result
+func addToolResults(messages []openai.ChatCompletionMessage) []openai.ChatCompletionMessage {
+	var result []openai.ChatCompletionMessage
+	for i := 0; i < len(messages); i++ {
+		message := messages[i]
+		result = append(result, message)
+		answered := map[string]bool{}
+		for i+1 < len(messages) && messages[i+1].Role == openai.ChatMessageRoleTool {
+			i++
+			answered[messages[i].ToolCallID] = true
+			result = append(result, messages[i])
+		}
+		for _, toolCall := range message.ToolCalls {
+			if !answered[toolCall.ID] {
+				result = append(result, openai.ChatCompletionMessage{
+					Role:       openai.ChatMessageRoleTool,
+					ToolCallID: toolCall.ID,
+					Content:    toolNotRun,
+				})
+			}
 		}
-		fmt.Printf("%s\n", choice.Message.Content)
 	}
-	chatCompletionRequest.Messages = append(chatCompletionRequest.Messages, resp.Choices[0].Message)
-
-	return nil
+	return result
 }
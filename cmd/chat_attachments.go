@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	slashCommandAttach = "/attach"
+)
+
+// attachFiles adds image or PDF files, or image URLs, to be sent with the next message, checking that each file can be read
+func attachFiles(f *ChatFlags, files []string) error {
+	for _, file := range files {
+		if isImageURL(file) {
+			f.attachments = append(f.attachments, file)
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("unable to attach %s: %w", file, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("unable to attach %s: is a directory", file)
+		}
+		f.attachments = append(f.attachments, file)
+	}
+	return nil
+}
+
+// newUserMessage creates the message to send, with any pending attachments as image parts.
+// Attachments are sent once, so are cleared when the message is created.
+func newUserMessage(f *ChatFlags, chatRequestString string) (openai.ChatCompletionMessage, error) {
+	if len(f.attachments) == 0 {
+		return openai.ChatCompletionMessage{
+			Role:    f.role,
+			Content: chatRequestString,
+		}, nil
+	}
+
+	content := []openai.ChatMessagePart{
+		{
+			Type: openai.ChatMessagePartTypeText,
+			Text: chatRequestString,
+		},
+	}
+	for _, file := range f.attachments {
+		parts, err := newAttachmentParts(file, f.pageRange, f.detail)
+		if err != nil {
+			return openai.ChatCompletionMessage{}, err
+		}
+		content = append(content, parts...)
+	}
+	f.attachments = nil
+
+	return openai.ChatCompletionMessage{
+		Role:         f.role,
+		MultiContent: content,
+	}, nil
+}
+
+// newAttachmentParts passes a URL through, or reads a file, sending the pages in the range of a PDF,
+// or an image, downscaled for the detail
+func newAttachmentParts(file string, pageRange PageRange, detail openai.ImageURLDetail) ([]openai.ChatMessagePart, error) {
+	if isImageURL(file) {
+		return []openai.ChatMessagePart{{
+			Type: openai.ChatMessagePartTypeImageURL,
+			ImageURL: &openai.ChatMessageImageURL{
+				URL:    file,
+				Detail: detail,
+			},
+		}}, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", file, err)
+	}
+	if http.DetectContentType(data) == pdfContentType {
+		return newPDFMessageParts(file, data, pageRange, detail)
+	}
+	part, err := newVisionImagePart(file, data, detail)
+	if err != nil {
+		return nil, err
+	}
+	return []openai.ChatMessagePart{part}, nil
+}
+
+// newVisionImagePart downscales an image for the detail, and encodes it as a data URL.
+// Prints the estimated image tokens for each image.
+func newVisionImagePart(name string, data []byte, detail openai.ImageURLDetail) (openai.ChatMessagePart, error) {
+	img, err := prepareVisionImage(data, detail)
+	if err != nil {
+		return openai.ChatMessagePart{}, fmt.Errorf("%s: %w", name, err)
+	}
+	if img.Downscaled() {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: downscaled from %dx%d to %dx%d, about %d image tokens\n", name,
+			img.OriginalBounds.Dx(), img.OriginalBounds.Dy(), img.Bounds.Dx(), img.Bounds.Dy(), img.Tokens)
+	} else {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %dx%d, about %d image tokens\n", name, img.Bounds.Dx(), img.Bounds.Dy(), img.Tokens)
+	}
+
+	return openai.ChatMessagePart{
+		Type: openai.ChatMessagePartTypeImageURL,
+		ImageURL: &openai.ChatMessageImageURL{
+			URL:    "data:" + img.ContentType + ";base64," + base64.StdEncoding.EncodeToString(img.Data),
+			Detail: detail,
+		},
+	}, nil
+}
+
+// messageText returns the text of a message, including the text parts of a message with attachments
+func messageText(message openai.ChatCompletionMessage) string {
+	if len(message.MultiContent) == 0 {
+		return message.Content
+	}
+	var text []string
+	for _, part := range message.MultiContent {
+		switch part.Type {
+		case openai.ChatMessagePartTypeText:
+			text = append(text, part.Text)
+		case openai.ChatMessagePartTypeImageURL:
+			text = append(text, "[image]")
+		}
+	}
+	return strings.Join(text, "\n")
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Chat Attachments", func() {
+	var f *ChatFlags
+	var imageFile string
+
+	BeforeEach(func() {
+		f = NewChatFlags()
+		f.role = openai.ChatMessageRoleUser
+		f.detail = openai.ImageURLDetailLow
+		imageFile = filepath.Join(GinkgoT().TempDir(), "image.png")
+		var buf bytes.Buffer
+		Ω(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 16)))).To(Succeed())
+		Ω(os.WriteFile(imageFile, buf.Bytes(), 0644)).To(Succeed())
+	})
+
+	It("should send a plain message without attachments", func() {
+		message, err := newUserMessage(f, "hello")
+		Ω(err).ToNot(HaveOccurred())
+		Ω(message.Content).To(Equal("hello"))
+		Ω(message.MultiContent).To(BeEmpty())
+	})
+
+	It("should send attachments with the next message only", func() {
+		Ω(attachFiles(f, []string{imageFile})).To(Succeed())
+
+		message, err := newUserMessage(f, "what is this?")
+		Ω(err).ToNot(HaveOccurred())
+		Ω(message.Role).To(Equal(openai.ChatMessageRoleUser))
+		Ω(message.MultiContent).To(HaveLen(2))
+		Ω(message.MultiContent[0].Text).To(Equal("what is this?"))
+		Ω(message.MultiContent[1].ImageURL.URL).To(HavePrefix("data:image/png;base64,"))
+		Ω(message.MultiContent[1].ImageURL.Detail).To(Equal(openai.ImageURLDetailLow))
+		Ω(messageText(message)).To(Equal("what is this?\n[image]"))
+
+		message, err = newUserMessage(f, "and now?")
+		Ω(err).ToNot(HaveOccurred())
+		Ω(message.Content).To(Equal("and now?"))
+		Ω(message.MultiContent).To(BeEmpty())
+	})
+
+	It("should pass URLs through without reading them", func() {
+		Ω(attachFiles(f, []string{"https://example.com/cat.png"})).To(Succeed())
+		message, err := newUserMessage(f, "what is this?")
+		Ω(err).ToNot(HaveOccurred())
+		Ω(message.MultiContent[1].ImageURL.URL).To(Equal("https://example.com/cat.png"))
+	})
+
+	It("should not attach files that are not images", func() {
+		textFile := filepath.Join(GinkgoT().TempDir(), "notes.txt")
+		Ω(os.WriteFile(textFile, []byte("just some notes"), 0644)).To(Succeed())
+		Ω(attachFiles(f, []string{textFile})).To(Succeed())
+		_, err := newUserMessage(f, "what is this?")
+		Ω(err).To(MatchError(ContainSubstring("unsupported image type text/plain")))
+	})
+
+	It("should not attach missing files or directories", func() {
+		Ω(attachFiles(f, []string{filepath.Join(GinkgoT().TempDir(), "missing.png")})).To(MatchError(ContainSubstring("unable to attach")))
+		Ω(attachFiles(f, []string{GinkgoT().TempDir()})).To(MatchError(ContainSubstring("is a directory")))
+		Ω(f.attachments).To(BeEmpty())
+	})
+
+	It("should queue the attach flag files for the first message", func() {
+		f.detailStr = string(openai.ImageURLDetailHigh)
+		f.attachFiles = []string{imageFile}
+		Ω(f.ValidateFlags()).To(Succeed())
+		Ω(f.detail).To(Equal(openai.ImageURLDetailHigh))
+		Ω(f.attachments).To(Equal([]string{imageFile}))
+	})
+
+	It("should handle /attach as a slash command", func() {
+		chat := &openai.ChatCompletionRequest{}
+		handled, err := runSlashCommand(f, NewChatContext(), chat, "/attach "+imageFile)
+		Ω(handled).To(BeTrue())
+		Ω(err).ToNot(HaveOccurred())
+		Ω(f.attachments).To(Equal([]string{imageFile}))
+
+		handled, err = runSlashCommand(f, NewChatContext(), chat, "/attach")
+		Ω(handled).To(BeTrue())
+		Ω(err).To(MatchError(ContainSubstring("usage")))
+	})
+})
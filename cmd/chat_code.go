@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/duanemay/chatgpt-cli/pkg/markdown"
+	"github.com/pterm/pterm"
+)
+
+// printCodeBlocks prints only the fenced code blocks from a response, separated by a blank line
+func printCodeBlocks(content string) {
+	var code []string
+	for _, block := range markdown.ExtractCodeBlocks(content) {
+		code = append(code, block.Code)
+	}
+	fmt.Print(strings.Join(code, "\n"))
+}
+
+// saveCodeBlocks writes each fenced code block in a response to its own file in dir.
+// Existing files are only overwritten after confirmation, in an interactive session.
+func saveCodeBlocks(chatContext *ChatContext, dir string, content string) error {
+	blocks := markdown.ExtractCodeBlocks(content)
+	if len(blocks) == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "No code blocks found in the response\n")
+		return nil
+	}
+
+	for _, block := range blocks {
+		fileName := getCodeBlockFileName(dir, block)
+		if _, err := os.Stat(fileName); err == nil && !confirmOverwrite(chatContext, fileName) {
+			_, _ = fmt.Fprintf(os.Stderr, "skipped existing file: %s\n", fileName)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+			return fmt.Errorf("directory creation error: %w", err)
+		}
+		if err := os.WriteFile(fileName, []byte(block.Code), 0644); err != nil {
+			return fmt.Errorf("file write error: %w", err)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "saved: %s\n", fileName)
+	}
+	return nil
+}
+
+// getCodeBlockFileName uses the filename suggested for the block, when it stays inside dir, otherwise a name is
+// generated with an extension for the block's language, numbered after the generated names already in dir
+func getCodeBlockFileName(dir string, block markdown.CodeBlock) string {
+	if block.Filename != "" && filepath.IsLocal(block.Filename) {
+		return filepath.Join(dir, block.Filename)
+	}
+	prefix := filepath.Join(dir, "code")
+	return fmt.Sprintf("%s-%02d%s", prefix, nextFileNumber(prefix), block.Extension())
+}
+
+// confirmOverwrite asks to overwrite an existing file, always false when not in an interactive session
+func confirmOverwrite(chatContext *ChatContext, fileName string) bool {
+	if !chatContext.InteractiveSession {
+		return false
+	}
+	overwrite, _ := pterm.DefaultInteractiveConfirm.WithDefaultText(fmt.Sprintf("%s exists, overwrite?", fileName)).Show()
+	return overwrite
+}
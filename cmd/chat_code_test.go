@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Chat Code Blocks", func() {
+	response := "Save this as `hello.sh`:\n```bash\necho hello\n```\nAnd some python\n```python\nprint('hi')\n```\n"
+
+	It("should save each code block to a file", func() {
+		dir := GinkgoT().TempDir()
+		Ω(saveCodeBlocks(NewChatContext(), dir, response)).To(Succeed())
+
+		Ω(os.ReadFile(filepath.Join(dir, "hello.sh"))).To(BeEquivalentTo("echo hello\n"))
+		Ω(os.ReadFile(filepath.Join(dir, "code-01.py"))).To(BeEquivalentTo("print('hi')\n"))
+	})
+
+	It("should number the generated names after those saved by an earlier run", func() {
+		dir := GinkgoT().TempDir()
+		Ω(saveCodeBlocks(NewChatContext(), dir, response)).To(Succeed())
+		Ω(saveCodeBlocks(NewChatContext(), dir, "```python\nprint('again')\n```\n```go\npackage main\n```\n")).To(Succeed())
+
+		Ω(os.ReadFile(filepath.Join(dir, "code-01.py"))).To(BeEquivalentTo("print('hi')\n"))
+		Ω(os.ReadFile(filepath.Join(dir, "code-02.py"))).To(BeEquivalentTo("print('again')\n"))
+		Ω(os.ReadFile(filepath.Join(dir, "code-03.go"))).To(BeEquivalentTo("package main\n"))
+	})
+
+	It("should not overwrite files outside an interactive session", func() {
+		dir := GinkgoT().TempDir()
+		Ω(os.WriteFile(filepath.Join(dir, "hello.sh"), []byte("original\n"), 0644)).To(Succeed())
+		Ω(saveCodeBlocks(NewChatContext(), dir, response)).To(Succeed())
+		Ω(os.ReadFile(filepath.Join(dir, "hello.sh"))).To(BeEquivalentTo("original\n"))
+	})
+
+	It("should not use filenames outside the directory", func() {
+		dir := GinkgoT().TempDir()
+		Ω(saveCodeBlocks(NewChatContext(), dir, "```go ../escape.go\npackage main\n```\n")).To(Succeed())
+		Ω(filepath.Join(dir, "code-01.go")).To(BeARegularFile())
+	})
+
+	It("should handle /save-code as a slash command", func() {
+		dir := GinkgoT().TempDir()
+		chat := &openai.ChatCompletionRequest{Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: "write a script"},
+			{Role: openai.ChatMessageRoleAssistant, Content: response},
+		}}
+		handled, err := runSlashCommand(NewChatFlags(), NewChatContext(), chat, "/save-code "+dir)
+		Ω(handled).To(BeTrue())
+		Ω(err).ToNot(HaveOccurred())
+		Ω(filepath.Join(dir, "hello.sh")).To(BeARegularFile())
+
+		handled, _ = runSlashCommand(NewChatFlags(), NewChatContext(), chat, "/etc/hosts has an error")
+		Ω(handled).To(BeFalse())
+	})
+})
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	slashCommandSaveCode = "/save-code"
+)
+
+// runSlashCommand runs a chat slash command, such as /save-code or /attach.
+// Returns false if the input is not a slash command, and should be sent as a message.
+func runSlashCommand(f *ChatFlags, chatContext *ChatContext, chatCompletionRequest *openai.ChatCompletionRequest, input string) (bool, error) {
+	name, args, _ := strings.Cut(strings.TrimSpace(input), " ")
+	args = strings.TrimSpace(args)
+
+	switch name {
+	case slashCommandSaveCode:
+		dir := args
+		if dir == "" {
+			dir = f.extractCodeDir
+		}
+		if dir == "" {
+			dir = "."
+		}
+		return true, saveCodeBlocks(chatContext, dir, lastAssistantMessage(chatCompletionRequest))
+	case slashCommandAttach:
+		if args == "" {
+			return true, fmt.Errorf("usage: %s <path>", slashCommandAttach)
+		}
+		if err := attachFiles(f, []string{args}); err != nil {
+			return true, err
+		}
+		fmt.Printf("Attached %s, it will be sent with the next message\n", args)
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// lastAssistantMessage returns the content of the most recent assistant message in the session
+func lastAssistantMessage(chatCompletionRequest *openai.ChatCompletionRequest) string {
+	for i := len(chatCompletionRequest.Messages) - 1; i >= 0; i-- {
+		if chatCompletionRequest.Messages[i].Role == openai.ChatMessageRoleAssistant {
+			return chatCompletionRequest.Messages[i].Content
+		}
+	}
+	return ""
+}
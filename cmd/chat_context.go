@@ -2,6 +2,8 @@ package cmd
 
 type ChatContext struct {
 	InteractiveSession bool
+	RenderMarkdown     bool
+	SuppressResponse   bool
 }
 
 func NewChatContext() *ChatContext {
@@ -1,5 +1,7 @@
 package cmd
 
+import "github.com/sashabaranov/go-openai"
+
 type ChatFlags struct {
 	model                string
 	role                 string
@@ -9,19 +11,104 @@ type ChatFlags struct {
 	temperature          float32
 	maxCompletionTokens  int
 	topP                 float32
+	persona              string
+	personasFile         string
+	tools                []openai.Tool
+	render               bool
+	noRender             bool
+	extractCodeDir       string
+	onlyCode             bool
+	attachFiles          []string
+	detailStr            string
+	detail               openai.ImageURLDetail
+	pages                string
+	pageRange            PageRange
+
+	// attachments are the files to send with the next message
+	attachments []string
+
+	// speak reads each response aloud, with the speech flags
+	speak  bool
+	play   bool
+	speech *SpeechFlags
 }
 
 func NewChatFlags() *ChatFlags {
-	return &ChatFlags{}
+	return &ChatFlags{
+		speech: NewSpeechFlags(),
+	}
+}
+
+// ValidateFlags checks the image detail, PDF pages, and speech flags, and queues the attached files for the first message
+func (f *ChatFlags) ValidateFlags() error {
+	detail, err := parseImageDetail(f.detailStr)
+	if err != nil {
+		return err
+	}
+	f.detail = detail
+	if f.pageRange, err = parsePageRange(f.pages); err != nil {
+		return err
+	}
+	if f.speak {
+		if err := f.speech.ValidateFlags(); err != nil {
+			return err
+		}
+		f.speech.Play = f.play
+		f.speech.NoticesToStderr = true
+	}
+	return attachFiles(f, f.attachFiles)
 }
 
 func ChatFlagsFromVisionFlags(f *VisionFlags) *ChatFlags {
 	return &ChatFlags{
 		model:                f.model,
+		detailStr:            f.DetailStr,
+		detail:               f.Detail,
+		attachFiles:          f.inputFiles,
+		pages:                f.pages,
 		role:                 f.role,
 		initialSystemMessage: f.initialSystemMessage,
 		sessionFile:          f.sessionFile,
 		skipWriteSessionFile: f.skipWriteSessionFile,
+		persona:              f.persona,
+		personasFile:         f.personasFile,
+
+		temperature:         defaultTemperature,
+		maxCompletionTokens: defaultMaxCompletionTokens,
+		topP:                defaultTopP,
+	}
+}
+
+func ChatFlagsFromAskShellFlags(f *AskShellFlags) *ChatFlags {
+	return &ChatFlags{
+		model: f.model,
+		role:  defaultRole,
+
+		temperature:         defaultTemperature,
+		maxCompletionTokens: defaultMaxCompletionTokens,
+		topP:                defaultTopP,
+	}
+}
+
+// ChatFlagsFromTranscriptionFlags chats about a transcript with the summary model, saving the session to sessionFile
+func ChatFlagsFromTranscriptionFlags(f *TranscriptionFlags, sessionFile string) *ChatFlags {
+	return &ChatFlags{
+		model:       f.SummaryModel,
+		role:        defaultRole,
+		sessionFile: sessionFile,
+
+		temperature:         defaultTemperature,
+		maxCompletionTokens: defaultMaxCompletionTokens,
+		topP:                defaultTopP,
+	}
+}
+
+func ChatFlagsFromGitFlags(f *GitFlags) *ChatFlags {
+	return &ChatFlags{
+		model:        f.model,
+		role:         defaultRole,
+		persona:      f.persona,
+		personasFile: f.personasFile,
 
 		temperature:         defaultTemperature,
 		maxCompletionTokens: defaultMaxCompletionTokens,
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"golang.org/x/image/draw"
+)
+
+const (
+	// lowDetailMaxSide is the resolution the model sees an image at with low detail
+	lowDetailMaxSide = 512
+	// highDetailMaxSide and highDetailMaxShortSide are the largest image the model uses with high detail,
+	// it is first fit within a square, then its short side reduced
+	highDetailMaxSide      = 2048
+	highDetailMaxShortSide = 768
+	// imageTileSize is the size of the tiles the model looks at with high detail
+	imageTileSize = 512
+	// imageBaseTokens and imageTileTokens make up the cost of an image
+	imageBaseTokens = 85
+	imageTileTokens = 170
+	// downscaledJpegQuality is the quality used to re-encode downscaled JPEG images
+	downscaledJpegQuality = 90
+)
+
+// visionContentTypes are the image types the vision models accept
+var visionContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// visionImage is an image prepared to attach to a message
+type visionImage struct {
+	ContentType    string
+	Data           []byte
+	OriginalBounds image.Rectangle
+	Bounds         image.Rectangle
+	Tokens         int
+}
+
+// Downscaled reports if the image was made smaller to send
+func (v visionImage) Downscaled() bool {
+	return v.Bounds != v.OriginalBounds
+}
+
+// isImageURL reports if an attachment is a URL, sent to the model as is, rather than a file to upload
+func isImageURL(file string) bool {
+	return strings.HasPrefix(file, "https://")
+}
+
+// prepareVisionImage detects the image type, and downscales the image if it is larger than the model uses for the detail
+func prepareVisionImage(data []byte, detail openai.ImageURLDetail) (visionImage, error) {
+	contentType := http.DetectContentType(data)
+	if !visionContentTypes[contentType] {
+		return visionImage{}, fmt.Errorf("unsupported image type %s, must be PNG, JPEG, GIF, or WebP", contentType)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return visionImage{}, fmt.Errorf("image decode error: %w", err)
+	}
+
+	result := visionImage{
+		ContentType:    contentType,
+		Data:           data,
+		OriginalBounds: image.Rect(0, 0, config.Width, config.Height),
+	}
+	result.Bounds = visionImageBounds(result.OriginalBounds, detail)
+	result.Tokens = estimateImageTokens(result.Bounds, detail)
+	if !result.Downscaled() {
+		return result, nil
+	}
+
+	img, err := decodeImage(data, contentType)
+	if err != nil {
+		return visionImage{}, err
+	}
+	scaled := image.NewRGBA(result.Bounds)
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	// photos stay JPEG, anything that might have transparency becomes PNG
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: downscaledJpegQuality})
+	} else {
+		result.ContentType = "image/png"
+		err = png.Encode(&buf, scaled)
+	}
+	if err != nil {
+		return visionImage{}, fmt.Errorf("image encode error: %w", err)
+	}
+	result.Data = buf.Bytes()
+	return result, nil
+}
+
+// visionImageBounds returns the largest size the model uses for an image with the detail, never larger than the image
+func visionImageBounds(bounds image.Rectangle, detail openai.ImageURLDetail) image.Rectangle {
+	width, height := float64(bounds.Dx()), float64(bounds.Dy())
+	scale := 1.0
+	if detail == openai.ImageURLDetailLow {
+		scale = min(scale, lowDetailMaxSide/max(width, height))
+	} else {
+		scale = min(scale, highDetailMaxSide/max(width, height))
+		scale = min(scale, highDetailMaxShortSide/(min(width, height)*scale)*scale)
+	}
+	if scale >= 1 {
+		return bounds
+	}
+	return image.Rect(0, 0, max(1, int(width*scale)), max(1, int(height*scale)))
+}
+
+// estimateImageTokens estimates the input tokens for an image of the size, a fixed cost for low detail,
+// or a cost for each tile for high detail
+func estimateImageTokens(bounds image.Rectangle, detail openai.ImageURLDetail) int {
+	if detail == openai.ImageURLDetailLow {
+		return imageBaseTokens
+	}
+	tiles := ((bounds.Dx() + imageTileSize - 1) / imageTileSize) * ((bounds.Dy() + imageTileSize - 1) / imageTileSize)
+	return imageBaseTokens + imageTileTokens*tiles
+}
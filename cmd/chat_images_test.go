@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Chat Images", func() {
+	encode := func(width, height int, encoder func(*bytes.Buffer, image.Image) error) []byte {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for x := 0; x < width; x++ {
+			img.Set(x, 0, color.RGBA{R: 0xff, A: 0xff})
+		}
+		var buf bytes.Buffer
+		Ω(encoder(&buf, img)).To(Succeed())
+		return buf.Bytes()
+	}
+	encodePNG := func(buf *bytes.Buffer, img image.Image) error { return png.Encode(buf, img) }
+	encodeJPEG := func(buf *bytes.Buffer, img image.Image) error { return jpeg.Encode(buf, img, nil) }
+	encodeGIF := func(buf *bytes.Buffer, img image.Image) error { return gif.Encode(buf, img, nil) }
+
+	It("should detect the content type", func() {
+		for contentType, data := range map[string][]byte{
+			"image/png":  encode(10, 10, encodePNG),
+			"image/jpeg": encode(10, 10, encodeJPEG),
+			"image/gif":  encode(10, 10, encodeGIF),
+		} {
+			img, err := prepareVisionImage(data, openai.ImageURLDetailAuto)
+			Ω(err).ToNot(HaveOccurred())
+			Ω(img.ContentType).To(Equal(contentType))
+			Ω(img.Downscaled()).To(BeFalse())
+			Ω(img.Data).To(Equal(data))
+		}
+	})
+
+	It("should downscale large images for the detail", func() {
+		img, err := prepareVisionImage(encode(4000, 1000, encodeJPEG), openai.ImageURLDetailHigh)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(img.Downscaled()).To(BeTrue())
+		Ω(img.ContentType).To(Equal("image/jpeg"))
+		config, err := jpeg.DecodeConfig(bytes.NewReader(img.Data))
+		Ω(err).ToNot(HaveOccurred())
+		Ω([]int{config.Width, config.Height}).To(Equal([]int{2048, 512}))
+
+		img, err = prepareVisionImage(encode(1000, 2000, encodeGIF), openai.ImageURLDetailLow)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(img.ContentType).To(Equal("image/png"))
+		config, err = png.DecodeConfig(bytes.NewReader(img.Data))
+		Ω(err).ToNot(HaveOccurred())
+		Ω([]int{config.Width, config.Height}).To(Equal([]int{256, 512}))
+	})
+
+	It("should fit high detail images to a short side of 768", func() {
+		Ω(visionImageBounds(image.Rect(0, 0, 2048, 2048), openai.ImageURLDetailHigh)).To(Equal(image.Rect(0, 0, 768, 768)))
+		Ω(visionImageBounds(image.Rect(0, 0, 1024, 1024), openai.ImageURLDetailAuto)).To(Equal(image.Rect(0, 0, 768, 768)))
+		Ω(visionImageBounds(image.Rect(0, 0, 700, 300), openai.ImageURLDetailHigh)).To(Equal(image.Rect(0, 0, 700, 300)))
+	})
+
+	It("should estimate image tokens", func() {
+		Ω(estimateImageTokens(image.Rect(0, 0, 2048, 2048), openai.ImageURLDetailLow)).To(Equal(85))
+		Ω(estimateImageTokens(image.Rect(0, 0, 768, 768), openai.ImageURLDetailHigh)).To(Equal(85 + 170*4))
+		Ω(estimateImageTokens(image.Rect(0, 0, 512, 512), openai.ImageURLDetailAuto)).To(Equal(85 + 170))
+	})
+
+	It("should reject files that are not images", func() {
+		_, err := prepareVisionImage([]byte("%PDF-1.7"), openai.ImageURLDetailAuto)
+		Ω(err).To(MatchError(ContainSubstring("unsupported image type")))
+	})
+})
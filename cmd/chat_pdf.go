@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/duanemay/chatgpt-cli/pkg/pdf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/sashabaranov/go-openai"
+	"golang.org/x/image/tiff"
+)
+
+const pdfContentType = "application/pdf"
+
+var disablePDFConfigDir sync.Once
+
+// PageRange is the pages of a document to send, numbered from 1. A Last of 0 is the last page.
+type PageRange struct {
+	First int
+	Last  int
+}
+
+// parsePageRange parses a page, such as 3, or a range, such as 1-5, 4-, or -2. An empty range is all pages.
+func parsePageRange(spec string) (PageRange, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return PageRange{First: 1}, nil
+	}
+
+	first, last, isRange := strings.Cut(spec, "-")
+	if !isRange {
+		last = first
+	}
+	pages := PageRange{First: 1}
+	var err error
+	if first != "" {
+		if pages.First, err = strconv.Atoi(strings.TrimSpace(first)); err != nil || pages.First < 1 {
+			return PageRange{}, fmt.Errorf("pages must be a page or range of pages, such as 3 or 1-5")
+		}
+	}
+	if last != "" {
+		if pages.Last, err = strconv.Atoi(strings.TrimSpace(last)); err != nil || pages.Last < pages.First {
+			return PageRange{}, fmt.Errorf("pages must be a page or range of pages, such as 3 or 1-5")
+		}
+	}
+	return pages, nil
+}
+
+// pages returns the page numbers in the range for a document with the number of pages
+func (r PageRange) pages(pageCount int) ([]int, error) {
+	last := r.Last
+	if last == 0 || last > pageCount {
+		last = pageCount
+	}
+	if r.First > last {
+		return nil, fmt.Errorf("page %d is past the end of the document, which has %d pages", r.First, pageCount)
+	}
+	var pages []int
+	for page := r.First; page <= last; page++ {
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// newPDFMessageParts sends each page in the range as its text, labeled with its page number, followed by the
+// images embedded in the page, such as photos or scanned pages. Pages are not rasterized, so drawings made of
+// vector graphics, such as most charts, are not sent.
+func newPDFMessageParts(file string, data []byte, pageRange PageRange, detail openai.ImageURLDetail) ([]openai.ChatMessagePart, error) {
+	ctx, err := readPDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	pages, err := pageRange.pages(ctx.PageCount)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	var parts []openai.ChatMessagePart
+	name := filepath.Base(file)
+	for _, pageNumber := range pages {
+		text, err := pdf.PageText(ctx, pageNumber)
+		if err != nil {
+			return nil, fmt.Errorf("%s: page %d: %w", file, pageNumber, err)
+		}
+		images, err := pdfPageImages(ctx, pageNumber)
+		if err != nil {
+			return nil, fmt.Errorf("%s: page %d: %w", file, pageNumber, err)
+		}
+
+		label := fmt.Sprintf("Page %d of %s:", pageNumber, name)
+		switch {
+		case text != "":
+			label += "\n" + text
+		case len(images) == 0:
+			label += "\n(no text or images)"
+		}
+		parts = append(parts, openai.ChatMessagePart{Type: openai.ChatMessagePartTypeText, Text: label})
+		for i, image := range images {
+			part, err := newVisionImagePart(fmt.Sprintf("%s page %d image %d", file, pageNumber, i+1), image, detail)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		}
+	}
+	return parts, nil
+}
+
+// readPDF reads the PDF, for the text and images of its pages
+func readPDF(data []byte) (*model.Context, error) {
+	disablePDFConfigDir.Do(api.DisableConfigDir)
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.EXTRACTIMAGES
+	ctx, err := api.ReadValidateAndOptimize(bytes.NewReader(data), conf)
+	if err != nil {
+		return nil, fmt.Errorf("PDF read error: %w", err)
+	}
+	return ctx, nil
+}
+
+// pdfPageImages returns the images on a page, in the order they are stored, skipping the page thumbnail.
+// TIFF images, which the models do not accept, are converted to PNG.
+func pdfPageImages(ctx *model.Context, pageNumber int) ([][]byte, error) {
+	images, err := pdfcpu.ExtractPageImages(ctx, pageNumber, false)
+	if err != nil {
+		return nil, fmt.Errorf("image extract error: %w", err)
+	}
+	objectNumbers := make([]int, 0, len(images))
+	for objectNumber, image := range images {
+		if !image.Thumb {
+			objectNumbers = append(objectNumbers, objectNumber)
+		}
+	}
+	slices.Sort(objectNumbers)
+
+	var result [][]byte
+	for _, objectNumber := range objectNumbers {
+		image := images[objectNumber]
+		data, err := io.ReadAll(image)
+		if err != nil {
+			return nil, fmt.Errorf("image extract error: %w", err)
+		}
+		if image.FileType == "tif" {
+			if data, err = tiffToPNG(data); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, data)
+	}
+	return result, nil
+}
+
+func tiffToPNG(data []byte) ([]byte, error) {
+	img, err := tiff.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("TIFF decode error: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("PNG encode error: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// textPDF builds a PDF with a page showing each of the texts
+func textPDF(texts ...string) []byte {
+	var objects []string
+	kids := ""
+	for i, text := range texts {
+		page := 4 + i*2
+		kids += fmt.Sprintf("%d 0 R ", page)
+		content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", page+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+	objects = append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(texts)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}, objects...)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		_, _ = fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	_, _ = fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		_, _ = fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	_, _ = fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+var _ = Describe("Chat PDF Attachments", func() {
+	It("should parse page ranges", func() {
+		Ω(parsePageRange("")).To(Equal(PageRange{First: 1}))
+		Ω(parsePageRange("3")).To(Equal(PageRange{First: 3, Last: 3}))
+		Ω(parsePageRange("1-5")).To(Equal(PageRange{First: 1, Last: 5}))
+		Ω(parsePageRange("4-")).To(Equal(PageRange{First: 4}))
+		Ω(parsePageRange("-2")).To(Equal(PageRange{First: 1, Last: 2}))
+
+		for _, spec := range []string{"0", "5-3", "one", "1-two"} {
+			_, err := parsePageRange(spec)
+			Ω(err).To(MatchError(ContainSubstring("pages must be")), spec)
+		}
+	})
+
+	It("should limit page ranges to the document", func() {
+		Ω(PageRange{First: 2, Last: 10}.pages(3)).To(Equal([]int{2, 3}))
+		Ω(PageRange{First: 1}.pages(2)).To(Equal([]int{1, 2}))
+		_, err := PageRange{First: 4}.pages(3)
+		Ω(err).To(MatchError(ContainSubstring("past the end")))
+	})
+
+	It("should send the text of each page in the range, labeled with its page number", func() {
+		parts, err := newPDFMessageParts("notes.pdf", textPDF("First page", "Second page", "Third page"), PageRange{First: 2, Last: 3}, openai.ImageURLDetailAuto)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(parts).To(HaveLen(2))
+		Ω(parts[0].Type).To(Equal(openai.ChatMessagePartTypeText))
+		Ω(parts[0].Text).To(Equal("Page 2 of notes.pdf:\nSecond page"))
+		Ω(parts[1].Text).To(Equal("Page 3 of notes.pdf:\nThird page"))
+	})
+
+	It("should send the images of scanned pages", func() {
+		img := image.NewRGBA(image.Rect(0, 0, 40, 60))
+		for x := 0; x < 40; x++ {
+			img.Set(x, 10, color.RGBA{B: 0xff, A: 0xff})
+		}
+		var scan bytes.Buffer
+		Ω(jpeg.Encode(&scan, img, nil)).To(Succeed())
+		var doc bytes.Buffer
+		disablePDFConfigDir.Do(api.DisableConfigDir)
+		Ω(api.ImportImages(nil, &doc, []io.Reader{bytes.NewReader(scan.Bytes())}, nil, nil)).To(Succeed())
+
+		parts, err := newPDFMessageParts("scan.pdf", doc.Bytes(), PageRange{First: 1}, openai.ImageURLDetailLow)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(parts).To(HaveLen(2))
+		Ω(parts[0].Text).To(Equal("Page 1 of scan.pdf:"))
+		Ω(parts[1].Type).To(Equal(openai.ChatMessagePartTypeImageURL))
+		Ω(parts[1].ImageURL.URL).To(HavePrefix("data:image/jpeg;base64,"))
+		Ω(parts[1].ImageURL.Detail).To(Equal(openai.ImageURLDetailLow))
+	})
+
+	It("should send the text of a page with its images", func() {
+		img := image.NewRGBA(image.Rect(0, 0, 40, 60))
+		var photo bytes.Buffer
+		Ω(jpeg.Encode(&photo, img, nil)).To(Succeed())
+		var doc, stamped bytes.Buffer
+		disablePDFConfigDir.Do(api.DisableConfigDir)
+		Ω(api.ImportImages(nil, &doc, []io.Reader{bytes.NewReader(photo.Bytes())}, nil, nil)).To(Succeed())
+		caption, err := api.TextWatermark("Figure 1", "points:12, scale:1 abs", true, false, types.POINTS)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(api.AddWatermarks(bytes.NewReader(doc.Bytes()), &stamped, nil, caption, nil)).To(Succeed())
+
+		parts, err := newPDFMessageParts("figure.pdf", stamped.Bytes(), PageRange{First: 1}, openai.ImageURLDetailLow)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(parts).To(HaveLen(2))
+		Ω(parts[0].Text).To(Equal("Page 1 of figure.pdf:\nFigure 1"))
+		Ω(parts[1].Type).To(Equal(openai.ChatMessagePartTypeImageURL))
+	})
+
+	It("should attach PDF files by their content", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "document")
+		Ω(os.WriteFile(file, textPDF("Hello PDF"), 0644)).To(Succeed())
+		f := NewChatFlags()
+		f.pages = "1"
+		f.detailStr = string(openai.ImageURLDetailAuto)
+		f.attachFiles = []string{file}
+		Ω(f.ValidateFlags()).To(Succeed())
+
+		message, err := newUserMessage(f, "summarize this")
+		Ω(err).ToNot(HaveOccurred())
+		Ω(messageText(message)).To(Equal("summarize this\nPage 1 of document:\nHello PDF"))
+	})
+})
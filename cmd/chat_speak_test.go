@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Chat Speak", func() {
+	It("should save the audio next to the session", func() {
+		f := NewChatFlags()
+		f.sessionFile = "sessions/pairing.json"
+		Ω(speechOutputPrefix(f)).To(Equal("sessions/pairing"))
+
+		f.sessionFile = ""
+		Ω(speechOutputPrefix(f)).To(HavePrefix("chat-"))
+	})
+
+	It("should number the audio after the files already saved for the session", func() {
+		dir := GinkgoT().TempDir()
+		prefix := filepath.Join(dir, "pairing")
+		Ω(nextFileNumber(prefix)).To(Equal(1))
+
+		for _, name := range []string{"pairing-01.mp3", "pairing-03.wav", "pairing-notes.mp3", "other-07.mp3", "pairing.json"} {
+			Ω(os.WriteFile(filepath.Join(dir, name), nil, 0644)).To(Succeed())
+		}
+		Ω(nextFileNumber(prefix)).To(Equal(4))
+	})
+
+	It("should validate the speech flags only when speaking", func() {
+		f := NewChatFlags()
+		f.detailStr = string(openai.ImageURLDetailAuto)
+		Ω(f.ValidateFlags()).To(Succeed())
+
+		f.speak = true
+		f.play = true
+		f.speech.Speed = 1.0
+		f.speech.ModelStr = string(openai.TTSModel1)
+		f.speech.VoiceStr = "no-good"
+		Ω(f.ValidateFlags()).To(MatchError(ContainSubstring("voice must be")))
+
+		f.speech.VoiceStr = string(openai.VoiceNova)
+		Ω(f.ValidateFlags()).To(Succeed())
+		Ω(f.speech.Voice).To(Equal(openai.VoiceNova))
+		Ω(f.speech.Play).To(BeTrue())
+		Ω(f.speech.NoticesToStderr).To(BeTrue())
+	})
+
+	It("should have the speech flags", func() {
+		chatCmd, _, err := NewRootCmd().Find([]string{"chat"})
+		Ω(err).ToNot(HaveOccurred())
+		for _, name := range []string{FlagSpeak, FlagPlay, FlagSpeechModel, FlagVoice, FlagSpeed, FlagInstructions} {
+			Ω(chatCmd.PersistentFlags().Lookup(name)).ToNot(BeNil(), name)
+		}
+	})
+})
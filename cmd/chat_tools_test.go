@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Chat Tools", func() {
+	It("should answer a tool call, so the next message can be sent", func() {
+		var requests []openai.ChatCompletionRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var request openai.ChatCompletionRequest
+			Ω(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+			requests = append(requests, request)
+
+			delta := `{"content": "done"}`
+			if len(requests) == 1 {
+				delta = `{"tool_calls": [{"index": 0, "id": "call_1", "type": "function", "function": {"name": "lookup_file", "arguments": "{}"}}]}`
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprintf(w, "data: {\"choices\": [{\"index\": 0, \"delta\": %s}]}\n\ndata: [DONE]\n\n", delta)
+		}))
+		defer server.Close()
+
+		config := openai.DefaultConfig("key")
+		config.BaseURL = server.URL + "/v1"
+		client := openai.NewClientWithConfig(config)
+		f := NewChatFlags()
+		chatContext := &ChatContext{SuppressResponse: true}
+		chat := &openai.ChatCompletionRequest{Tools: []openai.Tool{{Type: openai.ToolTypeFunction, Function: &openai.FunctionDefinition{Name: "lookup_file"}}}}
+
+		Ω(sendChatMessages(f, chatContext, chat, client, "find the file")).To(Succeed())
+		Ω(sendChatMessages(f, chatContext, chat, client, "thanks")).To(Succeed())
+
+		Ω(requests).To(HaveLen(2))
+		Ω(requests[1].Messages).To(HaveLen(4))
+		Ω(requests[1].Messages[1].ToolCalls).To(HaveLen(1))
+		Ω(requests[1].Messages[2].Role).To(Equal(openai.ChatMessageRoleTool))
+		Ω(requests[1].Messages[2].ToolCallID).To(Equal("call_1"))
+		Ω(requests[1].Messages[3].Content).To(Equal("thanks"))
+		Ω(chat.Messages).To(HaveLen(5))
+	})
+
+	It("should answer the tool calls of a saved session", func() {
+		messages := addToolResults([]openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: "find the files"},
+			{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{ID: "call_1"}, {ID: "call_2"}}},
+			{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "found"},
+			{Role: openai.ChatMessageRoleUser, Content: "thanks"},
+		})
+		Ω(messages).To(HaveLen(5))
+		Ω(messages[2].Content).To(Equal("found"))
+		Ω(messages[3].ToolCallID).To(Equal("call_2"))
+		Ω(messages[3].Content).To(Equal(toolNotRun))
+		Ω(messages[4].Content).To(Equal("thanks"))
+	})
+})
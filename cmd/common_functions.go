@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/duanemay/chatgpt-cli/pkg/markdown"
 	pkgerrors "github.com/pkg/errors"
 	"github.com/pterm/pterm"
 	"github.com/sashabaranov/go-openai"
@@ -40,6 +41,19 @@ func detectTerminal() bool {
 	return term.IsTerminal(int(os.Stdin.Fd()))
 }
 
+// detectRenderMarkdown determines if responses should be rendered as Markdown.
+// --no-render and --render take priority, otherwise responses are rendered in interactive sessions
+// when stdout is also a terminal, and left raw when piped.
+func detectRenderMarkdown(render bool, noRender bool, chatContext *ChatContext) bool {
+	if noRender {
+		return false
+	}
+	if render {
+		return true
+	}
+	return chatContext.InteractiveSession && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
 // loadOrCreateChatCompletionRequest
 // if a sessionFile is provided, and it exists, then it is loaded
 // if a sessionFile is provided, and it does not exist, then it is created
@@ -61,6 +75,7 @@ func loadOrCreateChatCompletionRequest(f *ChatFlags, chatContext *ChatContext) *
 			chat.Temperature = f.temperature
 			chat.MaxCompletionTokens = f.maxCompletionTokens
 			chat.TopP = f.topP
+			chat.Tools = f.tools
 		}
 	}
 
@@ -72,6 +87,7 @@ func loadOrCreateChatCompletionRequest(f *ChatFlags, chatContext *ChatContext) *
 			Temperature:         f.temperature,
 			MaxCompletionTokens: f.maxCompletionTokens,
 			TopP:                f.topP,
+			Tools:               f.tools,
 		}
 		if chatContext.InteractiveSession && shouldWriteSession(f) {
 			fmt.Printf("  session will be saved to: %s\n", f.sessionFile)
@@ -81,6 +97,104 @@ func loadOrCreateChatCompletionRequest(f *ChatFlags, chatContext *ChatContext) *
 	return chat
 }
 
+// apiChatCompletionRequest returns a copy of the session request suitable for sending to the API.
+// Session metadata is kept locally only, as the API rejects metadata unless the completion is stored.
+func apiChatCompletionRequest(chat *openai.ChatCompletionRequest) openai.ChatCompletionRequest {
+	request := *chat
+	request.Metadata = nil
+	return request
+}
+
+// streamChatCompletion sends the request to ChatGPT, prints the response as it arrives, and returns the
+// completed response message. The spinner is stopped when the first part of the response is received.
+func streamChatCompletion(chatContext *ChatContext, client *openai.Client, chatCompletionRequest *openai.ChatCompletionRequest, successSpinner *pterm.SpinnerPrinter) (openai.ChatCompletionMessage, error) {
+	message := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), apiChatCompletionRequest(chatCompletionRequest))
+	if err != nil {
+		successSpinner.Fail(err.Error())
+		return message, err
+	}
+	defer func(stream *openai.ChatCompletionStream) { _ = stream.Close() }(stream)
+
+	var out io.Writer = os.Stdout
+	var renderer *markdown.Renderer
+	if chatContext.SuppressResponse {
+		out = io.Discard
+	} else if chatContext.RenderMarkdown {
+		renderer = markdown.NewRenderer(os.Stdout)
+		out = renderer
+	}
+
+	var content strings.Builder
+	started := false
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if !started {
+				successSpinner.Fail(err.Error())
+			}
+			return message, err
+		}
+		if !started {
+			started = true
+			successSpinner.Success()
+			if chatContext.InteractiveSession && !chatContext.SuppressResponse {
+				AiFmt.Printf("\nChatGPT response:\n")
+			}
+		}
+		if len(resp.Choices) == 0 {
+			continue
+		}
+
+		delta := resp.Choices[0].Delta
+		if delta.Content != "" {
+			content.WriteString(delta.Content)
+			_, _ = io.WriteString(out, delta.Content)
+		}
+		message.ToolCalls = appendToolCallDeltas(message.ToolCalls, delta.ToolCalls)
+	}
+	if !started {
+		successSpinner.Success()
+	}
+
+	if renderer != nil {
+		if err := renderer.Flush(); err != nil {
+			return message, err
+		}
+	} else if !chatContext.SuppressResponse {
+		fmt.Printf("\n")
+	}
+	for _, toolCall := range message.ToolCalls {
+		fmt.Printf("tool call, not run: %s(%s)\n", toolCall.Function.Name, toolCall.Function.Arguments)
+	}
+
+	message.Content = content.String()
+	return message, nil
+}
+
+// appendToolCallDeltas merges streamed tool call fragments into the complete tool calls
+func appendToolCallDeltas(toolCalls []openai.ToolCall, deltas []openai.ToolCall) []openai.ToolCall {
+	for _, delta := range deltas {
+		index := len(toolCalls)
+		if delta.Index != nil {
+			index = *delta.Index
+		}
+		for len(toolCalls) <= index {
+			toolCalls = append(toolCalls, openai.ToolCall{Type: openai.ToolTypeFunction})
+		}
+		if delta.ID != "" {
+			toolCalls[index].ID = delta.ID
+		}
+		toolCalls[index].Function.Name += delta.Function.Name
+		toolCalls[index].Function.Arguments += delta.Function.Arguments
+	}
+	return toolCalls
+}
+
 // shouldWriteSession determines if the sessionFile should be written to disk
 // Only writes if --session-file was explicitly provided and --skip-write-session is not set
 func shouldWriteSession(f *ChatFlags) bool {
@@ -101,15 +215,15 @@ func loadSessionFile(sessionFile string) (chat *openai.ChatCompletionRequest) {
 }
 
 // writeSessionFile writes the sessionFile to disk
-func writeSessionFile(f *ChatFlags, chat *openai.ChatCompletionRequest) {
+func writeSessionFile(f *ChatFlags, chat *openai.ChatCompletionRequest) error {
 	objJson, err := json.MarshalIndent(chat, "", "  ")
 	if err != nil {
-		log.WithError(err).Fatal()
+		return fmt.Errorf("session encode error: %w", err)
 	}
-	err = os.WriteFile(f.sessionFile, objJson, 0600)
-	if err != nil {
-		log.WithError(err).Fatal()
+	if err := os.WriteFile(f.sessionFile, objJson, 0600); err != nil {
+		return fmt.Errorf("session write error: %w", err)
 	}
+	return nil
 }
 
 // readUserInput reads user input either interactively via pterm or from stdin.
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -14,4 +18,13 @@ var _ = Describe("Common", func() {
 			})
 		})
 	})
+
+	Describe("writeSessionFile", func() {
+		It("should return an error when the session can't be written", func() {
+			f := NewChatFlags()
+			f.sessionFile = filepath.Join(GinkgoT().TempDir(), "missing", "session.json")
+			err := writeSessionFile(f, &openai.ChatCompletionRequest{})
+			Expect(err).To(MatchError(ContainSubstring("session write error")))
+		})
+	})
 })
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	commitMsgInstructions = "You write git commit messages following the Conventional Commits specification. " +
+		"Given a diff, reply with only the commit message: a subject line of the form `type(scope): summary` " +
+		"of no more than 72 characters, a blank line, then a short body explaining what changed and why, " +
+		"wrapped at 72 characters. Do not wrap the message in a code block."
+	reviewInstructions = "You are an experienced code reviewer. Review the diff, and reply in Markdown with these sections: " +
+		"## Summary, a short description of the change. " +
+		"## Issues, a list of bugs, security problems, and risky changes, each with the file, line, severity (high, medium, low), and a suggested fix. " +
+		"## Suggestions, a list of smaller improvements to readability, naming, and tests. " +
+		"## Verdict, one of approve, approve with changes, or request changes, with a sentence explaining why."
+	explainInstructions = "You explain git commits to developers. Given a commit and its diff, explain what changed, " +
+		"why it was most likely changed, and any risks or follow up work. Use Markdown, and refer to files by name."
+	combineDiffRequest = "The diff was too large to send at once, so it was split by file, and these are the results for each part. " +
+		"Combine them into a single result, following the original instructions.\n\n"
+)
+
+func NewGitCmd(rootFlags *RootFlags) *cobra.Command {
+	gitFlags := NewGitFlags()
+	var cmd = &cobra.Command{
+		Use:   "git",
+		Short: "Commit messages, code reviews, and explanations from git",
+		Long:  "Generate commit messages, review diffs, and explain commits from the git repository in the current directory",
+	}
+
+	cmd.AddCommand(newGitCommitMsgCmd(rootFlags, gitFlags))
+	cmd.AddCommand(newGitReviewCmd(rootFlags, gitFlags))
+	cmd.AddCommand(newGitExplainCmd(rootFlags, gitFlags))
+
+	AddModelFlag(&gitFlags.model, cmd.PersistentFlags())
+	AddPersonaFlag(&gitFlags.persona, cmd.PersistentFlags())
+	AddPersonasFileFlag(&gitFlags.personasFile, cmd.PersistentFlags())
+	AddTemplateFileFlag(&gitFlags.templateFile, cmd.PersistentFlags())
+	AddMaxDiffSizeFlag(&gitFlags.maxDiffSize, cmd.PersistentFlags())
+	_ = cmd.MarkPersistentFlagRequired(FlagApiKey)
+
+	return cmd
+}
+
+func newGitCommitMsgCmd(rootFlags *RootFlags, gitFlags *GitFlags) *cobra.Command {
+	chatContext := NewChatContext()
+	var cmd = &cobra.Command{
+		Use:   "commit-msg",
+		Short: "Generate a conventional commit message for the staged changes",
+		Long:  "Generate a conventional commit message from git diff --staged, optionally installing itself as the prepare-commit-msg hook",
+		RunE:  gitCommitMsgCmdRunner(rootFlags, gitFlags, chatContext),
+	}
+	setChatContext(cmd, chatContext)
+
+	AddInstallHookFlag(&gitFlags.installHook, cmd.Flags())
+	AddHookFlag(&gitFlags.hook, cmd.Flags())
+	_ = cmd.Flags().MarkHidden(FlagHook)
+
+	return cmd
+}
+
+func newGitReviewCmd(rootFlags *RootFlags, gitFlags *GitFlags) *cobra.Command {
+	chatContext := NewChatContext()
+	var cmd = &cobra.Command{
+		Use:   "review [range]",
+		Short: "Review a diff range, by default the uncommitted changes",
+		Long:  "Produce a structured code review of a diff range, such as main..feature, by default the uncommitted changes",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  gitReviewCmdRunner(rootFlags, gitFlags, chatContext),
+	}
+	setChatContext(cmd, chatContext)
+	return cmd
+}
+
+func newGitExplainCmd(rootFlags *RootFlags, gitFlags *GitFlags) *cobra.Command {
+	chatContext := NewChatContext()
+	var cmd = &cobra.Command{
+		Use:   "explain <sha>",
+		Short: "Explain a commit",
+		Long:  "Explain what a commit changed, and why",
+		Args:  cobra.ExactArgs(1),
+		RunE:  gitExplainCmdRunner(rootFlags, gitFlags, chatContext),
+	}
+	setChatContext(cmd, chatContext)
+	return cmd
+}
+
+func gitCommitMsgCmdRunner(rootFlags *RootFlags, gitFlags *GitFlags, chatContext *ChatContext) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		log.Debugf("gitCommitMsgCmd called")
+		if gitFlags.installHook {
+			hookFile, err := installCommitMsgHook()
+			if err != nil {
+				log.WithError(err).Fatal()
+			}
+			fmt.Printf("%s\n", hookFile)
+			return nil
+		}
+		if gitFlags.hook {
+			// a failing hook would block the commit, so problems are reported and the message left for the user
+			if err := runCommitMsgHook(cmd, rootFlags, gitFlags, chatContext, args); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "%s\n", ErrorFmt.Sprintf("chatgpt-cli: unable to generate commit message: %v", err))
+			}
+			return nil
+		}
+
+		diff, err := runGit("diff", "--staged")
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+		if strings.TrimSpace(diff) == "" {
+			ErrorFmt.Printf("No staged changes, exiting...\n")
+			return nil
+		}
+
+		if _, err := runGitChat(cmd, rootFlags, gitFlags, chatContext, commitMsgInstructions, diff); err != nil {
+			log.WithError(err).Fatal()
+		}
+		return nil
+	}
+}
+
+// runCommitMsgHook writes a generated commit message into the message file, when git has not already provided
+// a message from -m, a template, a merge, a squash, or an amended commit.
+// Args are those given to the prepare-commit-msg hook: the message file, and optionally the message source and commit.
+func runCommitMsgHook(cmd *cobra.Command, rootFlags *RootFlags, gitFlags *GitFlags, chatContext *ChatContext, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("the commit message file is required")
+	}
+	if len(args) > 1 && args[1] != "" {
+		return nil
+	}
+
+	diff, err := runGit("diff", "--staged")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return nil
+	}
+
+	chatContext.SuppressResponse = true
+	message, err := runGitChat(cmd, rootFlags, gitFlags, chatContext, commitMsgInstructions, diff)
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(args[0], []byte(strings.TrimSpace(message)+"\n"+string(existing)), 0644)
+}
+
+func gitReviewCmdRunner(rootFlags *RootFlags, gitFlags *GitFlags, chatContext *ChatContext) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		log.Debugf("gitReviewCmd called")
+		diffRange := "HEAD"
+		if len(args) > 0 {
+			diffRange = args[0]
+		}
+
+		diff, err := runGit("diff", diffRange)
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+		if strings.TrimSpace(diff) == "" {
+			ErrorFmt.Printf("No changes in %s, exiting...\n", diffRange)
+			return nil
+		}
+
+		if _, err := runGitChat(cmd, rootFlags, gitFlags, chatContext, reviewInstructions, diff); err != nil {
+			log.WithError(err).Fatal()
+		}
+		return nil
+	}
+}
+
+func gitExplainCmdRunner(rootFlags *RootFlags, gitFlags *GitFlags, chatContext *ChatContext) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		log.Debugf("gitExplainCmd called")
+		commit, err := runGit("show", "--stat", "--patch", args[0])
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+
+		if _, err := runGitChat(cmd, rootFlags, gitFlags, chatContext, explainInstructions, commit); err != nil {
+			log.WithError(err).Fatal()
+		}
+		return nil
+	}
+}
+
+func printGitBanner(f *ChatFlags) {
+	TitleFmt.Printf("ChatGPT CLI v%s\n", version)
+	if f.persona != "" {
+		fmt.Printf("persona: %s\n", f.persona)
+	}
+	fmt.Printf("model: %s\n", f.model)
+}
+
+// runGitChat sets up the client and chat parameters, then sends the diff to ChatGPT with the instructions.
+// Returns the final response.
+func runGitChat(cmd *cobra.Command, rootFlags *RootFlags, gitFlags *GitFlags, chatContext *ChatContext, instructions string, diff string) (string, error) {
+	if err := gitFlags.ValidateFlags(); err != nil {
+		return "", err
+	}
+	chatFlags := ChatFlagsFromGitFlags(gitFlags)
+	if err := applyPersona(cmd, chatFlags); err != nil {
+		return "", err
+	}
+	if gitFlags.templateFile != "" {
+		template, err := os.ReadFile(gitFlags.templateFile)
+		if err != nil {
+			return "", err
+		}
+		instructions = string(template)
+	}
+
+	if !chatContext.SuppressResponse {
+		// git commands do not read stdin, so only treat the session as interactive when the output is not captured
+		chatContext.InteractiveSession = detectTerminal() && term.IsTerminal(int(os.Stdout.Fd()))
+		chatContext.RenderMarkdown = detectRenderMarkdown(false, false, chatContext)
+		if chatContext.InteractiveSession {
+			printGitBanner(chatFlags)
+		}
+	}
+	client, err := setupOpenAIClient(rootFlags.apikey)
+	if err != nil {
+		return "", err
+	}
+
+	systemMessage := instructions
+	if chatFlags.initialSystemMessage != "" {
+		systemMessage = chatFlags.initialSystemMessage + "\n\n" + instructions
+	}
+	return sendDiffMessages(gitFlags, chatFlags, chatContext, client, systemMessage, diff)
+}
+
+// sendDiffMessages sends the diff to ChatGPT, returning the response.
+// A diff larger than --max-diff-size is split into chunks by file, each chunk is sent on its own,
+// and then the results for each chunk are combined into the final response.
+func sendDiffMessages(gitFlags *GitFlags, chatFlags *ChatFlags, chatContext *ChatContext, client *openai.Client, systemMessage string, diff string) (string, error) {
+	chunks := chunkDiff(diff, gitFlags.maxDiffSize)
+	if len(chunks) == 1 {
+		return sendGitChatMessage(chatFlags, chatContext, client, systemMessage, chunks[0])
+	}
+
+	log.Debugf("diff split into %d chunks", len(chunks))
+	chunkContext := *chatContext
+	chunkContext.SuppressResponse = true
+	var results []string
+	for i, chunk := range chunks {
+		result, err := sendGitChatMessage(chatFlags, &chunkContext, client, systemMessage,
+			fmt.Sprintf("Part %d of %d of the diff:\n\n%s", i+1, len(chunks), chunk))
+		if err != nil {
+			return "", err
+		}
+		results = append(results, fmt.Sprintf("Result for part %d:\n%s", i+1, result))
+	}
+
+	combined, err := combineDiffResults(results, gitFlags.maxDiffSize-len(combineDiffRequest), func(results string) (string, error) {
+		return sendGitChatMessage(chatFlags, &chunkContext, client, systemMessage, combineDiffRequest+results)
+	})
+	if err != nil {
+		return "", err
+	}
+	return sendGitChatMessage(chatFlags, chatContext, client, systemMessage, combineDiffRequest+combined)
+}
+
+// sendGitChatMessage sends a single message in a new session, with the system message
+func sendGitChatMessage(chatFlags *ChatFlags, chatContext *ChatContext, client *openai.Client, systemMessage string, message string) (string, error) {
+	chatCompletionRequest := loadOrCreateChatCompletionRequest(chatFlags, chatContext)
+	chatCompletionRequest.Messages = append(chatCompletionRequest.Messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleSystem,
+		Content: systemMessage,
+	})
+	if err := sendChatMessages(chatFlags, chatContext, chatCompletionRequest, client, message); err != nil {
+		return "", err
+	}
+	return lastAssistantMessage(chatCompletionRequest), nil
+}
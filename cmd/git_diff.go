@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	diffFileHeader    = "diff --git "
+	diffTruncatedNote = "\n... [diff truncated] ...\n"
+	hookMarker        = "# installed by chatgpt-cli"
+)
+
+// runGit runs git with the arguments, returning its output, or its error output in the error
+func runGit(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.Command("git", args...)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// splitDiffByFile splits a unified diff into one part per file.
+// Any header before the first file, such as the commit from git show, is kept with the first file.
+func splitDiffByFile(diff string) []string {
+	var parts []string
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(line, diffFileHeader) && strings.Contains(current.String(), diffFileHeader) {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// chunkDiff groups the per file parts of a diff into chunks of no more than maxSize characters.
+// A single file larger than maxSize is truncated.
+func chunkDiff(diff string, maxSize int) []string {
+	return chunkTexts(splitDiffByFile(diff), maxSize, "")
+}
+
+// chunkTexts groups texts, joined by the separator, into chunks of no more than maxSize characters.
+// A single text larger than maxSize is truncated.
+func chunkTexts(texts []string, maxSize int, separator string) []string {
+	var chunks []string
+	var current strings.Builder
+	for _, text := range texts {
+		text = truncateDiffText(text, maxSize)
+		if current.Len() > 0 && current.Len()+len(separator)+len(text) > maxSize {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString(separator)
+		}
+		current.WriteString(text)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// truncateDiffText cuts text larger than maxSize characters at the end of a line, or of a character when the
+// first line is too long, and notes that it was truncated
+func truncateDiffText(text string, maxSize int) string {
+	if len(text) <= maxSize {
+		return text
+	}
+	end := maxSize - len(diffTruncatedNote)
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	if line := strings.LastIndexByte(text[:end], '\n'); line > 0 {
+		end = line
+	}
+	return text[:end] + diffTruncatedNote
+}
+
+// combineDiffResults joins the results for each part of a diff, to be sent in a request of no more than maxSize
+// characters. Results too large to send together are combined in groups with send, until they fit.
+func combineDiffResults(results []string, maxSize int, send func(results string) (string, error)) (string, error) {
+	for {
+		if combined := strings.Join(results, "\n\n"); len(combined) <= maxSize {
+			return combined, nil
+		}
+		if len(results) == 1 {
+			return truncateDiffText(results[0], maxSize), nil
+		}
+
+		// at least two results fit in each group, so there are fewer results each time around
+		for i, result := range results {
+			results[i] = truncateDiffText(result, (maxSize-2)/2)
+		}
+		groups := chunkTexts(results, maxSize, "\n\n")
+		results = nil
+		for i, group := range groups {
+			result, err := send(group)
+			if err != nil {
+				return "", err
+			}
+			results = append(results, fmt.Sprintf("Result for group %d:\n%s", i+1, result))
+		}
+	}
+}
+
+// installCommitMsgHook writes a prepare-commit-msg hook, that runs this executable, into the repository's hooks directory.
+// An existing hook is only replaced if it was installed by chatgpt-cli.
+func installCommitMsgHook() (string, error) {
+	hooksDir, err := runGit("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	hookFile := filepath.Join(strings.TrimSpace(hooksDir), "prepare-commit-msg")
+
+	if existing, err := os.ReadFile(hookFile); err == nil && !strings.Contains(string(existing), hookMarker) {
+		return "", fmt.Errorf("a prepare-commit-msg hook already exists: %s", hookFile)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("unable to find executable: %w", err)
+	}
+	quoted := "'" + strings.ReplaceAll(executable, "'", `'\''`) + "'"
+	script := fmt.Sprintf("#!/bin/sh\n%s\nexec %s git commit-msg --%s \"$@\"\n", hookMarker, quoted, FlagHook)
+
+	if err := os.MkdirAll(filepath.Dir(hookFile), 0755); err != nil {
+		return "", fmt.Errorf("directory creation error: %w", err)
+	}
+	if err := os.WriteFile(hookFile, []byte(script), 0755); err != nil {
+		return "", fmt.Errorf("file write error: %w", err)
+	}
+	return hookFile, nil
+}
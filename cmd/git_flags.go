@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+type GitFlags struct {
+	model        string
+	persona      string
+	personasFile string
+	templateFile string
+	maxDiffSize  int
+	installHook  bool
+	hook         bool
+}
+
+func NewGitFlags() *GitFlags {
+	return &GitFlags{}
+}
+
+func (f *GitFlags) ValidateFlags() error {
+	if f.maxDiffSize < 1000 {
+		return fmt.Errorf("max-diff-size must be at least 1000")
+	}
+	if f.templateFile != "" {
+		if _, err := os.Stat(f.templateFile); err != nil {
+			return fmt.Errorf("template file not found: %s", f.templateFile)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Git Command", func() {
+	twoFileDiff := "diff --git a/one.go b/one.go\n--- a/one.go\n+++ b/one.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/two.go b/two.go\n--- a/two.go\n+++ b/two.go\n@@ -1 +1 @@\n-c\n+d\n"
+
+	It("should find command, and subcommands", func() {
+		var thisCmd *cobra.Command
+		Ω(NewRootCmd().Commands()).To(ContainElement(HaveField("Use", "git"), &thisCmd))
+		Ω(thisCmd.Commands()).To(ContainElement(HaveField("Use", "commit-msg")))
+		Ω(thisCmd.Commands()).To(ContainElement(HaveField("Use", "review [range]")))
+		Ω(thisCmd.Commands()).To(ContainElement(HaveField("Use", "explain <sha>")))
+	})
+
+	It("should split a diff by file, keeping any header with the first file", func() {
+		parts := splitDiffByFile("commit abc123\n\n    message\n\n" + twoFileDiff)
+		Ω(parts).To(HaveLen(2))
+		Ω(parts[0]).To(HavePrefix("commit abc123"))
+		Ω(parts[0]).To(ContainSubstring("one.go"))
+		Ω(parts[1]).To(HavePrefix("diff --git a/two.go"))
+		Ω(strings.Join(parts, "")).To(Equal("commit abc123\n\n    message\n\n" + twoFileDiff))
+	})
+
+	It("should only chunk diffs that are too large", func() {
+		Ω(chunkDiff(twoFileDiff, 1000)).To(HaveLen(1))
+
+		chunks := chunkDiff(twoFileDiff, len(twoFileDiff)/2+10)
+		Ω(chunks).To(HaveLen(2))
+		Ω(chunks[1]).To(ContainSubstring("two.go"))
+	})
+
+	It("should truncate a single file that is too large", func() {
+		large := "diff --git a/big.txt b/big.txt\n" + strings.Repeat("+line\n", 1000)
+		chunks := chunkDiff(large, 1000)
+		Ω(chunks).To(HaveLen(1))
+		Ω(len(chunks[0])).To(BeNumerically("<=", 1000))
+		Ω(chunks[0]).To(HaveSuffix("+line" + diffTruncatedNote))
+	})
+
+	It("should truncate a long line without splitting a character", func() {
+		text := truncateDiffText("+"+strings.Repeat("é", 100), 50)
+		Ω(len(text)).To(BeNumerically("<=", 50))
+		Ω(utf8.ValidString(text)).To(BeTrue())
+		Ω(text).To(HaveSuffix(diffTruncatedNote))
+	})
+
+	It("should combine results in groups until they fit in one request", func() {
+		results := []string{strings.Repeat("a", 400), strings.Repeat("b", 400), strings.Repeat("c", 400)}
+		var sent []string
+		combined, err := combineDiffResults(results, 1000, func(results string) (string, error) {
+			Ω(len(results)).To(BeNumerically("<=", 1000))
+			sent = append(sent, results)
+			return fmt.Sprintf("summary %d", len(sent)), nil
+		})
+		Ω(err).ToNot(HaveOccurred())
+		Ω(sent).To(HaveLen(2))
+		Ω(sent[0]).To(ContainSubstring("bbbb"))
+		Ω(sent[1]).To(HavePrefix("cccc"))
+		Ω(combined).To(Equal("Result for group 1:\nsummary 1\n\nResult for group 2:\nsummary 2"))
+
+		combined, err = combineDiffResults([]string{"small", "results"}, 1000, nil)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(combined).To(Equal("small\n\nresults"))
+	})
+
+	It("should install the prepare-commit-msg hook", func() {
+		dir := GinkgoT().TempDir()
+		GinkgoT().Chdir(dir)
+		_, err := runGit("init", "-q")
+		Ω(err).ToNot(HaveOccurred())
+
+		hookFile, err := installCommitMsgHook()
+		Ω(err).ToNot(HaveOccurred())
+		Ω(hookFile).To(Equal(filepath.Join(".git", "hooks", "prepare-commit-msg")))
+		script, _ := os.ReadFile(hookFile)
+		Ω(string(script)).To(ContainSubstring("git commit-msg --hook \"$@\""))
+
+		// reinstalling our own hook is fine, but not replacing someone else's
+		_, err = installCommitMsgHook()
+		Ω(err).ToNot(HaveOccurred())
+		Ω(os.WriteFile(hookFile, []byte("#!/bin/sh\nexit 0\n"), 0755)).To(Succeed())
+		_, err = installCommitMsgHook()
+		Ω(err).To(HaveOccurred())
+	})
+})
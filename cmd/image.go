@@ -7,12 +7,12 @@ import (
 	"encoding/base64"
 	"fmt"
 	"image"
+	"image/gif"
 	"image/jpeg"
 	"image/png"
 	"net/http"
 	"time"
 
-	os2 "github.com/duanemay/chatgpt-cli/pkg/os"
 	"github.com/sashabaranov/go-openai"
 	"golang.org/x/image/webp"
 
@@ -33,12 +33,26 @@ func NewImageCmd(rootFlags *RootFlags) *cobra.Command {
 	}
 	setChatContext(cmd, chatContext)
 
+	cmd.AddCommand(newImageEditCmd(rootFlags, imageFlags))
+	cmd.AddCommand(newImageVariationsCmd(rootFlags, imageFlags))
+
 	AddImageModelFlag(&imageFlags.Model, cmd.PersistentFlags())
 	AddNumberImagesFlag(&imageFlags.NumberImages, cmd.PersistentFlags())
 	AddImageQualityFlag(&imageFlags.Quality, cmd.PersistentFlags())
 	AddImageSizeFlag(&imageFlags.Size, cmd.PersistentFlags())
 	AddImageStyleFlag(&imageFlags.Style, cmd.PersistentFlags())
+	AddImageBackgroundFlag(&imageFlags.Background, cmd.PersistentFlags())
+	AddImageFormatFlag(&imageFlags.ImageFormat, cmd.PersistentFlags())
+	AddImageCompressionFlag(&imageFlags.Compression, cmd.PersistentFlags())
+	AddImageModerationFlag(&imageFlags.Moderation, cmd.PersistentFlags())
+	AddImageOutputFormatFlag(&imageFlags.OutputFormat, cmd.PersistentFlags())
+	AddJpegQualityFlag(&imageFlags.JpegQuality, cmd.PersistentFlags())
+	AddJSONFlag(&imageFlags.JSON, cmd.PersistentFlags())
+	AddImagePreviewFlag(&imageFlags.Preview, cmd.PersistentFlags())
 	AddOutputPrefixFlag(&imageFlags.OutputPrefix, "image-"+time.Now().UTC().Format(time.RFC3339), cmd.PersistentFlags())
+	AddPromptsFileFlag(&imageFlags.PromptsFile, cmd.Flags())
+	AddConcurrencyFlag(&imageFlags.Concurrency, cmd.Flags())
+	AddRetriesFlag(&imageFlags.Retries, cmd.Flags())
 	_ = cmd.MarkPersistentFlagRequired("apikey")
 
 	return cmd
@@ -61,6 +75,13 @@ func imageCmdRunner(rootFlags *RootFlags, imageFlags *ImageFlags, chatContext *C
 			log.WithError(err).Fatal()
 		}
 
+		if imageFlags.PromptsFile != "" {
+			if err := runImageBatch(imageFlags, chatContext, client); err != nil {
+				log.WithError(err).Fatal()
+			}
+			return nil
+		}
+
 		reader := bufio.NewReader(os.Stdin)
 		for {
 			chatRequestString := readUserInput(chatContext, reader, "Enter description of the desired image")
@@ -83,107 +104,208 @@ func imageCmdRunner(rootFlags *RootFlags, imageFlags *ImageFlags, chatContext *C
 
 func printImageBanner(f *ImageFlags) {
 	TitleFmt.Printf("ChatGPT CLI v%s\n", version)
-	if f.Model == openai.CreateImageModelDallE2 {
+	switch f.Model {
+	case openai.CreateImageModelDallE2:
 		fmt.Printf("model: %s, numberImages: %d, size: %s\n", f.Model, f.NumberImages, f.Size)
-	} else {
+	case openai.CreateImageModelGptImage1:
+		fmt.Printf("model: %s, numberImages: %d, size: %s, quality: %s\n", f.Model, f.NumberImages, f.Size, f.Quality)
+		printGptImage1Options(f)
+		fmt.Printf("moderation: %s\n", f.Moderation)
+	default:
 		fmt.Printf("model: %s, size: %s, style: %s, quality: %s\n", f.Model, f.Size, f.Style, f.Quality)
 	}
+	if f.PromptsFile != "" {
+		fmt.Printf("prompts: %s, concurrency: %d, retries: %d\n", f.PromptsFile, f.Concurrency, f.Retries)
+		return
+	}
 	fmt.Printf("- Press TAB after entering a message to send.\n")
 	fmt.Printf("- Press TAB or CTRL+C with a blank message to terminate the session without sending.\n")
 }
 
+// printGptImage1Options prints the background and format options for GPT-Image-1
+func printGptImage1Options(f *ImageFlags) {
+	if f.ImageFormat == openai.CreateImageOutputFormatPNG {
+		fmt.Printf("background: %s, imageFormat: %s\n", f.Background, f.ImageFormat)
+	} else {
+		fmt.Printf("background: %s, imageFormat: %s, compression: %d\n", f.Background, f.ImageFormat, f.Compression)
+	}
+}
+
 // sendMessages sends messages to ChatGPT and prints the response
 func sendImageMessages(f *ImageFlags, chatContext *ChatContext, client *openai.Client, chatRequestString string) error {
 	mySpinner := newSpinner()
 	destination := "DALL-E"
-
-	var imageRequest openai.ImageRequest
 	if f.Model == openai.CreateImageModelGptImage1 {
 		destination = "GPT-Image-1"
-		imageRequest = openai.ImageRequest{
-			Prompt:  chatRequestString,
-			Model:   f.Model,
-			N:       f.NumberImages,
-			Quality: f.Quality,
-			Size:    f.Size,
-		}
-	} else {
-		imageRequest = openai.ImageRequest{
-			Prompt:         chatRequestString,
-			Model:          f.Model,
-			N:              f.NumberImages,
-			Quality:        f.Quality,
-			ResponseFormat: openai.CreateImageResponseFormatB64JSON,
-			Size:           f.Size,
-			Style:          f.Style,
-		}
 	}
+
 	successSpinner, _ := mySpinner.Start("Sending to " + destination + ", please wait...")
-	resp, err := client.CreateImage(context.Background(), imageRequest)
+	resp, err := client.CreateImage(context.Background(), newImageRequest(f, chatRequestString))
 	if err != nil {
 		successSpinner.Fail(err.Error())
 		return err
 	}
 	successSpinner.Success()
 
-	for _, data := range resp.Data {
-		if err := processImageData(data, f, chatContext); err != nil {
-			fmt.Printf("%v\n", err)
-			continue
+	metadata := newImageMetadata(f, chatRequestString, resp.Created)
+	_, err = saveImageResponse(resp, f, chatContext, metadata)
+	return err
+}
+
+// newImageRequest builds the request, with the parameters supported by the model
+func newImageRequest(f *ImageFlags, prompt string) openai.ImageRequest {
+	if f.Model == openai.CreateImageModelGptImage1 {
+		request := openai.ImageRequest{
+			Prompt:       prompt,
+			Model:        f.Model,
+			N:            f.NumberImages,
+			Quality:      f.Quality,
+			Size:         f.Size,
+			Background:   f.Background,
+			OutputFormat: f.ImageFormat,
+			Moderation:   f.Moderation,
 		}
+		if f.ImageFormat != openai.CreateImageOutputFormatPNG {
+			request.OutputCompression = f.Compression
+		}
+		return request
+	}
+	return openai.ImageRequest{
+		Prompt:         prompt,
+		Model:          f.Model,
+		N:              f.NumberImages,
+		Quality:        f.Quality,
+		ResponseFormat: openai.CreateImageResponseFormatB64JSON,
+		Size:           f.Size,
+		Style:          f.Style,
 	}
-	return nil
 }
 
-func getImageFileName(f *ImageFlags) string {
+func getImageFileName(f *ImageFlags, extension string) string {
 	thisImageCount := f.CurrentImageCount
 	f.CurrentImageCount = thisImageCount + 1
-	filename := fmt.Sprintf("%s-%02d.png", f.OutputPrefix, thisImageCount)
+	filename := fmt.Sprintf("%s-%02d%s", f.OutputPrefix, thisImageCount, extension)
 	return filename
 }
 
-func processImageData(data openai.ImageResponseDataInner, f *ImageFlags, chatContext *ChatContext) error {
+func processImageData(data openai.ImageResponseDataInner, f *ImageFlags, chatContext *ChatContext, metadata ImageMetadata) (ImageRecord, error) {
 	imgBytes, err := base64.StdEncoding.DecodeString(data.B64JSON)
 	if err != nil {
-		return fmt.Errorf("Base64 decode error: %w", err)
+		return ImageRecord{}, fmt.Errorf("Base64 decode error: %w", err)
 	}
 
-	r := bytes.NewReader(imgBytes)
+	output, extension, err := convertImage(imgBytes, f)
+	if err != nil {
+		return ImageRecord{}, err
+	}
+
+	metadata.RevisedPrompt = data.RevisedPrompt
+	if extension == ".png" {
+		output, err = addPNGMetadata(output, metadata)
+		if err != nil {
+			return ImageRecord{}, err
+		}
+	}
+
+	record := ImageRecord{
+		File:          getImageFileName(f, extension),
+		Bytes:         len(output),
+		ImageMetadata: metadata,
+	}
+	if err := os.WriteFile(record.File, output, 0644); err != nil {
+		return ImageRecord{}, fmt.Errorf("File write error: %w", err)
+	}
+	if extension != ".png" {
+		record.MetadataFile, err = writeImageMetadataFile(record.File, metadata)
+		if err != nil {
+			return ImageRecord{}, err
+		}
+	}
+
+	if f.JSON {
+		return record, nil
+	}
+	fmt.Printf("%s\n", record.File)
+	if chatContext.InteractiveSession && data.RevisedPrompt != "" {
+		fmt.Printf("revised prompt: %s\n", data.RevisedPrompt)
+	}
+	previewImage(f, chatContext, record.File, output)
+	return record, nil
+}
+
+// convertImage converts the image data to the output format, returning the data and its file extension.
+// Data already in the output format is kept as is, so it is not compressed again.
+func convertImage(imgBytes []byte, f *ImageFlags) ([]byte, string, error) {
 	contentType := http.DetectContentType(imgBytes)
-	var imgData image.Image
+	switch f.OutputFormat {
+	case ImageOutputFormatKeep:
+		extension, ok := imageExtensions[contentType]
+		if !ok {
+			return nil, "", fmt.Errorf("unsupported image content type: %s", contentType)
+		}
+		return imgBytes, extension, nil
+	case ImageOutputFormatWebpRaw:
+		if contentType != "image/webp" {
+			return nil, "", fmt.Errorf("webp-raw requires WebP image data, but the image is %s", contentType)
+		}
+		return imgBytes, ".webp", nil
+	case ImageOutputFormatJPEG:
+		if contentType == "image/jpeg" {
+			return imgBytes, ".jpg", nil
+		}
+		imgData, err := decodeImage(imgBytes, contentType)
+		if err != nil {
+			return nil, "", err
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, imgData, &jpeg.Options{Quality: f.JpegQuality}); err != nil {
+			return nil, "", fmt.Errorf("JPEG encode error: %w", err)
+		}
+		return buf.Bytes(), ".jpg", nil
+	default:
+		if contentType == "image/png" {
+			return imgBytes, ".png", nil
+		}
+		imgData, err := decodeImage(imgBytes, contentType)
+		if err != nil {
+			return nil, "", err
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, imgData); err != nil {
+			return nil, "", fmt.Errorf("PNG encode error: %w", err)
+		}
+		return buf.Bytes(), ".png", nil
+	}
+}
+
+func decodeImage(imgBytes []byte, contentType string) (image.Image, error) {
+	r := bytes.NewReader(imgBytes)
 	switch contentType {
 	case "image/png":
-		imgData, err = png.Decode(r)
+		imgData, err := png.Decode(r)
 		if err != nil {
-			return fmt.Errorf("PNG decode error: %w", err)
+			return nil, fmt.Errorf("PNG decode error: %w", err)
 		}
+		return imgData, nil
 	case "image/jpeg":
-		imgData, err = jpeg.Decode(r)
+		imgData, err := jpeg.Decode(r)
 		if err != nil {
-			return fmt.Errorf("JPEG decode error: %w", err)
+			return nil, fmt.Errorf("JPEG decode error: %w", err)
 		}
+		return imgData, nil
+	case "image/gif":
+		imgData, err := gif.Decode(r)
+		if err != nil {
+			return nil, fmt.Errorf("GIF decode error: %w", err)
+		}
+		return imgData, nil
 	case "image/webp":
-		imgData, err = webp.Decode(r)
+		imgData, err := webp.Decode(r)
 		if err != nil {
-			return fmt.Errorf("WebP decode error: %w", err)
+			return nil, fmt.Errorf("WebP decode error: %w", err)
 		}
+		return imgData, nil
 	default:
-		return fmt.Errorf("unsupported image content type: %s", contentType)
-	}
-
-	fileName := getImageFileName(f)
-	file, err := os.Create(fileName)
-	if err != nil {
-		return fmt.Errorf("File creation error: %w", err)
-	}
-	defer func(file *os.File) { _ = file.Close() }(file)
-
-	if err := png.Encode(file, imgData); err != nil {
-		return fmt.Errorf("PNG encode error: %w", err)
-	}
-	fmt.Printf("%s\n", fileName)
-	if chatContext.InteractiveSession {
-		os2.OpenBrowser(fileName)
+		return nil, fmt.Errorf("unsupported image content type: %s", contentType)
 	}
-	return nil
 }
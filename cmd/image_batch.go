@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// imageRetryBackoff is the wait before the first retry of a failed image request, doubling for each retry after
+var imageRetryBackoff = 2 * time.Second
+
+// ImagePrompt is a prompt from a prompts file, with optional overrides of the size, quality, and style flags
+type ImagePrompt struct {
+	Prompt  string `json:"prompt"`
+	Size    string `json:"size,omitempty"`
+	Quality string `json:"quality,omitempty"`
+	Style   string `json:"style,omitempty"`
+}
+
+// imageBatchItem is the outcome of generating the images for one prompt
+type imageBatchItem struct {
+	ImagePrompt
+	Flags  *ImageFlags
+	Result ImageResult
+	Err    error
+}
+
+// readImagePrompts reads a prompts file, with either a prompt on each line, or a JSON object on each line.
+// Blank lines, and lines starting with #, are skipped.
+func readImagePrompts(fileName string) ([]ImagePrompt, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read prompts: %w", err)
+	}
+	defer func(file *os.File) { _ = file.Close() }(file)
+
+	var prompts []ImagePrompt
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		prompt := ImagePrompt{Prompt: line}
+		if strings.HasPrefix(line, "{") {
+			prompt = ImagePrompt{}
+			if err := json.Unmarshal([]byte(line), &prompt); err != nil {
+				return nil, fmt.Errorf("%s line %d: %w", fileName, lineNumber, err)
+			}
+		}
+		if strings.TrimSpace(prompt.Prompt) == "" {
+			return nil, fmt.Errorf("%s line %d: prompt is required", fileName, lineNumber)
+		}
+		prompts = append(prompts, prompt)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read prompts: %w", err)
+	}
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("no prompts found in %s", fileName)
+	}
+	return prompts, nil
+}
+
+// newImageBatchItems applies each prompt's overrides to a copy of the flags, and validates them.
+// Each prompt's images are named with its number, so prompts can be generated at the same time.
+func newImageBatchItems(f *ImageFlags, prompts []ImagePrompt) ([]*imageBatchItem, error) {
+	items := make([]*imageBatchItem, len(prompts))
+	for i, prompt := range prompts {
+		itemFlags := *f
+		itemFlags.OutputPrefix = fmt.Sprintf("%s-%03d", f.OutputPrefix, i+1)
+		itemFlags.CurrentImageCount = 1
+		if prompt.Size != "" {
+			itemFlags.Size = prompt.Size
+		}
+		if prompt.Quality != "" {
+			itemFlags.Quality = prompt.Quality
+		}
+		if prompt.Style != "" {
+			itemFlags.Style = prompt.Style
+		}
+		if err := itemFlags.ValidateFlags(); err != nil {
+			return nil, fmt.Errorf("prompt %d: %w", i+1, err)
+		}
+		items[i] = &imageBatchItem{ImagePrompt: prompt, Flags: &itemFlags}
+	}
+	return items, nil
+}
+
+// runImageBatch generates the images for each prompt in the prompts file, no more than --concurrency at a time,
+// then writes the gallery
+func runImageBatch(f *ImageFlags, chatContext *ChatContext, client *openai.Client) error {
+	prompts, err := readImagePrompts(f.PromptsFile)
+	if err != nil {
+		return err
+	}
+	items, err := newImageBatchItems(f, prompts)
+	if err != nil {
+		return err
+	}
+
+	// previews of many images at once are more noise than help, unless asked for
+	itemContext := *chatContext
+	if f.Preview == ImagePreviewAuto {
+		itemContext.InteractiveSession = false
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	work := make(chan *imageBatchItem)
+	for range min(f.Concurrency, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range work {
+				resp, err := createImageWithRetries(f.Retries, func() (openai.ImageResponse, error) {
+					return client.CreateImage(context.Background(), newImageRequest(item.Flags, item.Prompt))
+				})
+
+				// saving is quick, so it is done one at a time, keeping the output and manifest in order
+				mu.Lock()
+				done++
+				if err != nil {
+					item.Err = err
+					_, _ = fmt.Fprintf(os.Stderr, "%s\n", ErrorFmt.Sprintf("[%d/%d] failed: %s: %v", done, len(items), item.Prompt, err))
+				} else {
+					_, _ = fmt.Fprintf(os.Stderr, "[%d/%d] done: %s\n", done, len(items), item.Prompt)
+					item.Result, item.Err = saveImageResponse(resp, item.Flags, &itemContext, newImageMetadata(item.Flags, item.Prompt, resp.Created))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, item := range items {
+		work <- item
+	}
+	close(work)
+	wg.Wait()
+
+	failed := 0
+	for _, item := range items {
+		if item.Err != nil {
+			failed++
+		}
+	}
+	galleryFile, err := writeImageGallery(f, items)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d prompts failed, gallery: %s", failed, len(items), galleryFile)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "%d of %d prompts succeeded, gallery: %s\n", len(items), len(items), galleryFile)
+	return nil
+}
+
+// createImageWithRetries calls create, retrying rate limits, server errors, and network errors, with a growing wait
+func createImageWithRetries(retries int, create func() (openai.ImageResponse, error)) (openai.ImageResponse, error) {
+	backoff := imageRetryBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := create()
+		if err == nil || attempt >= retries || !isRetryableImageError(err) {
+			return resp, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// isRetryableImageError reports if a request might succeed if tried again: rate limits, server errors, and network
+// errors, such as timeouts. Invalid requests, prompts refused by the safety system, and anything else are not retried.
+func isRetryableImageError(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests || apiErr.HTTPStatusCode >= http.StatusInternalServerError
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests || reqErr.HTTPStatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
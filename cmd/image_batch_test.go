@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Image Batch", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	writePrompts := func(content string) string {
+		fileName := filepath.Join(dir, "prompts.txt")
+		Ω(os.WriteFile(fileName, []byte(content), 0644)).To(Succeed())
+		return fileName
+	}
+	newFlags := func() *ImageFlags {
+		f := NewImageFlags()
+		f.Model = openai.CreateImageModelDallE3
+		f.NumberImages = 1
+		f.Size = openai.CreateImageSize1024x1024
+		f.Quality = openai.CreateImageQualityStandard
+		f.Style = openai.CreateImageStyleVivid
+		f.OutputPrefix = filepath.Join(dir, "cover")
+		return f
+	}
+
+	It("should read prompts from lines and JSON lines", func() {
+		prompts, err := readImagePrompts(writePrompts("# covers\nA monkey on a high wire\n\n" +
+			`{"prompt": "A lighthouse at dusk", "size": "1792x1024", "style": "natural"}` + "\n"))
+		Ω(err).ToNot(HaveOccurred())
+		Ω(prompts).To(Equal([]ImagePrompt{
+			{Prompt: "A monkey on a high wire"},
+			{Prompt: "A lighthouse at dusk", Size: "1792x1024", Style: "natural"},
+		}))
+	})
+
+	It("should report the line of an invalid prompt", func() {
+		_, err := readImagePrompts(writePrompts("A monkey\n{\"size\": \"1024x1024\"}\n"))
+		Ω(err).To(MatchError(ContainSubstring("line 2: prompt is required")))
+
+		_, err = readImagePrompts(writePrompts("A monkey\n{not json\n"))
+		Ω(err).To(MatchError(ContainSubstring("line 2")))
+
+		_, err = readImagePrompts(writePrompts("# nothing here\n"))
+		Ω(err).To(MatchError(ContainSubstring("no prompts found")))
+	})
+
+	It("should apply and validate each prompt's overrides", func() {
+		items, err := newImageBatchItems(newFlags(), []ImagePrompt{
+			{Prompt: "A monkey"},
+			{Prompt: "A lighthouse", Size: openai.CreateImageSize1792x1024, Quality: openai.CreateImageQualityHD},
+		})
+		Ω(err).ToNot(HaveOccurred())
+		Ω(items[0].Flags.Size).To(Equal(openai.CreateImageSize1024x1024))
+		Ω(items[0].Flags.OutputPrefix).To(Equal(filepath.Join(dir, "cover-001")))
+		Ω(items[1].Flags.Size).To(Equal(openai.CreateImageSize1792x1024))
+		Ω(items[1].Flags.Quality).To(Equal(openai.CreateImageQualityHD))
+		Ω(items[1].Flags.OutputPrefix).To(Equal(filepath.Join(dir, "cover-002")))
+
+		_, err = newImageBatchItems(newFlags(), []ImagePrompt{{Prompt: "A monkey"}, {Prompt: "A lighthouse", Size: "256x256"}})
+		Ω(err).To(MatchError(ContainSubstring("prompt 2: size must be one of")))
+	})
+
+	It("should retry only errors that may succeed later", func() {
+		original := imageRetryBackoff
+		imageRetryBackoff = time.Millisecond
+		defer func() { imageRetryBackoff = original }()
+
+		calls := 0
+		_, err := createImageWithRetries(2, func() (openai.ImageResponse, error) {
+			calls++
+			return openai.ImageResponse{}, &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}
+		})
+		Ω(err).To(HaveOccurred())
+		Ω(calls).To(Equal(3))
+
+		calls = 0
+		_, err = createImageWithRetries(2, func() (openai.ImageResponse, error) {
+			calls++
+			return openai.ImageResponse{}, &openai.APIError{HTTPStatusCode: http.StatusBadRequest}
+		})
+		Ω(err).To(HaveOccurred())
+		Ω(calls).To(Equal(1))
+
+		calls = 0
+		_, err = createImageWithRetries(2, func() (openai.ImageResponse, error) {
+			calls++
+			return openai.ImageResponse{}, errors.New("unable to decode the response")
+		})
+		Ω(err).To(HaveOccurred())
+		Ω(calls).To(Equal(1))
+
+		calls = 0
+		resp, err := createImageWithRetries(2, func() (openai.ImageResponse, error) {
+			calls++
+			if calls == 1 {
+				return openai.ImageResponse{}, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+			}
+			return openai.ImageResponse{Created: 1}, nil
+		})
+		Ω(err).ToNot(HaveOccurred())
+		Ω(resp.Created).To(Equal(int64(1)))
+		Ω(calls).To(Equal(2))
+	})
+
+	It("should fail when any prompt fails, after writing the gallery", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": {"message": "content policy violation", "type": "invalid_request_error"}}`))
+		}))
+		defer server.Close()
+		config := openai.DefaultConfig("test-key")
+		config.BaseURL = server.URL
+		f := newFlags()
+		f.PromptsFile = writePrompts("A monkey\n")
+		f.Concurrency = 1
+
+		// the progress goes to stderr, so it is kept out of the test output
+		stderr := os.Stderr
+		progress, err := os.Create(filepath.Join(dir, "progress.txt"))
+		Ω(err).ToNot(HaveOccurred())
+		os.Stderr = progress
+		err = runImageBatch(f, NewChatContext(), openai.NewClientWithConfig(config))
+		os.Stderr = stderr
+		Ω(progress.Close()).To(Succeed())
+
+		Ω(err).To(MatchError(ContainSubstring("1 of 1 prompts failed")))
+		Ω(filepath.Join(dir, imageGalleryFileName)).To(BeARegularFile())
+		Ω(os.ReadFile(progress.Name())).To(ContainSubstring("[1/1] failed: A monkey"))
+	})
+
+	It("should write a gallery of each prompt's images", func() {
+		items, err := newImageBatchItems(newFlags(), []ImagePrompt{{Prompt: "A monkey <on a wire>"}, {Prompt: "A lighthouse"}})
+		Ω(err).ToNot(HaveOccurred())
+		var cover bytes.Buffer
+		Ω(png.Encode(&cover, image.NewNRGBA(image.Rect(0, 0, 1024, 768)))).To(Succeed())
+		Ω(os.WriteFile(filepath.Join(dir, "cover-001-01.png"), cover.Bytes(), 0644)).To(Succeed())
+		items[0].Result.Images = []ImageRecord{{
+			File:          filepath.Join(dir, "cover-001-01.png"),
+			ImageMetadata: ImageMetadata{RevisedPrompt: "A monkey in a banana costume"},
+		}}
+		items[1].Err = errors.New("content policy violation")
+
+		fileName, err := writeImageGallery(newFlags(), items)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(fileName).To(Equal(filepath.Join(dir, imageGalleryFileName)))
+
+		html, err := os.ReadFile(fileName)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(html).To(ContainSubstring(`<a href="cover-001-01.png"><img src="thumbnails/cover-001-01.png"`))
+		Ω(html).To(ContainSubstring("A monkey &lt;on a wire&gt;"))
+		Ω(html).To(ContainSubstring("A monkey in a banana costume"))
+		Ω(html).To(ContainSubstring("model: dall-e-3, size: 1024x1024, quality: standard, style: vivid"))
+		Ω(html).To(ContainSubstring(`<p class="error">content policy violation</p>`))
+
+		thumbnail, err := os.Open(filepath.Join(dir, imageThumbnailDir, "cover-001-01.png"))
+		Ω(err).ToNot(HaveOccurred())
+		defer func() { _ = thumbnail.Close() }()
+		config, err := png.DecodeConfig(thumbnail)
+		Ω(err).ToNot(HaveOccurred())
+		Ω([]int{config.Width, config.Height}).To(Equal([]int{512, 384}))
+	})
+})
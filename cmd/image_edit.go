@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	maxGptImage1EditInputs   = 16
+	maxGptImage1EditFileSize = 50 * 1024 * 1024
+	maxDalle2EditFileSize    = 4 * 1024 * 1024
+)
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func newImageEditCmd(rootFlags *RootFlags, imageFlags *ImageFlags) *cobra.Command {
+	chatContext := NewChatContext()
+	var cmd = &cobra.Command{
+		Use:   "edit",
+		Short: "Edit an image",
+		Long:  "Edit or extend input images from a description, optionally only within the transparent areas of a mask",
+		RunE:  imageEditCmdRunner(rootFlags, imageFlags, chatContext),
+	}
+	setChatContext(cmd, chatContext)
+
+	AddImageInputFlag(&imageFlags.InputFiles, cmd.Flags())
+	AddMaskFlag(&imageFlags.MaskFile, cmd.Flags())
+	AddMaskRectFlag(&imageFlags.MaskRect, cmd.Flags())
+	_ = cmd.MarkFlagRequired(FlagImageInput)
+
+	return cmd
+}
+
+func imageEditCmdRunner(rootFlags *RootFlags, imageFlags *ImageFlags, chatContext *ChatContext) func(cmd *cobra.Command, args []string) error {
+	return func(_ *cobra.Command, _ []string) error {
+		log.Debugf("imageEditCmd called")
+		err := imageFlags.ValidateEditFlags()
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+
+		chatContext.InteractiveSession = detectTerminal()
+		if chatContext.InteractiveSession {
+			printImageEditBanner(imageFlags)
+		}
+		client, err := setupOpenAIClient(rootFlags.apikey)
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+		mask, err := loadImageEditMask(imageFlags)
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			chatRequestString := readUserInput(chatContext, reader, "Enter description of the desired edit")
+			if len(chatRequestString) == 0 {
+				ErrorFmt.Printf("No Image Request to Send, exiting...\n")
+				return nil
+			}
+
+			if err := sendImageEditMessages(imageFlags, chatContext, client, rootFlags.apikey, mask, chatRequestString); err != nil {
+				log.WithError(err).Fatal()
+			}
+
+			if !chatContext.InteractiveSession {
+				break
+			}
+		}
+		return nil
+	}
+}
+
+func printImageEditBanner(f *ImageFlags) {
+	TitleFmt.Printf("ChatGPT CLI v%s\n", version)
+	if f.Model == openai.CreateImageModelDallE2 {
+		fmt.Printf("model: %s, numberImages: %d, size: %s\n", f.Model, f.NumberImages, f.Size)
+	} else {
+		fmt.Printf("model: %s, numberImages: %d, size: %s, quality: %s\n", f.Model, f.NumberImages, f.Size, f.Quality)
+		printGptImage1Options(f)
+	}
+	fmt.Printf("input: %s\n", strings.Join(f.InputFiles, ", "))
+	if f.MaskFile != "" {
+		fmt.Printf("mask: %s\n", f.MaskFile)
+	} else if f.MaskRect != "" {
+		fmt.Printf("mask-rect: %s\n", f.MaskRect)
+	}
+	fmt.Printf("- Press TAB after entering a message to send.\n")
+	fmt.Printf("- Press TAB or CTRL+C with a blank message to terminate the session without sending.\n")
+}
+
+// loadImageEditMask reads the mask file, or generates a mask from the mask rectangle.
+// Returns nil when no mask was given.
+func loadImageEditMask(f *ImageFlags) ([]byte, error) {
+	if f.MaskFile != "" {
+		return os.ReadFile(f.MaskFile)
+	}
+	if f.MaskRect == "" {
+		return nil, nil
+	}
+	rect, err := parseMaskRect(f.MaskRect)
+	if err != nil {
+		return nil, err
+	}
+	_, config, err := readImageFileInfo(f.InputFiles[0], maxGptImage1EditFileSize)
+	if err != nil {
+		return nil, err
+	}
+	return createRectMask(config.Width, config.Height, rect)
+}
+
+// sendImageEditMessages sends the input images, mask, and description, and saves the edited images
+func sendImageEditMessages(f *ImageFlags, chatContext *ChatContext, client *openai.Client, apikey string, mask []byte, chatRequestString string) error {
+	mySpinner := newSpinner()
+	destination := "DALL-E"
+	if f.Model == openai.CreateImageModelGptImage1 {
+		destination = "GPT-Image-1"
+	}
+	successSpinner, _ := mySpinner.Start("Sending to " + destination + ", please wait...")
+
+	var resp openai.ImageResponse
+	var err error
+	if f.Model == openai.CreateImageModelGptImage1 {
+		resp, err = createGptImage1Edit(context.Background(), openai.DefaultConfig(apikey).BaseURL, apikey, f, mask, chatRequestString)
+	} else {
+		resp, err = createDalle2Edit(context.Background(), client, f, mask, chatRequestString)
+	}
+	if err != nil {
+		successSpinner.Fail(err.Error())
+		return err
+	}
+	successSpinner.Success()
+
+	metadata := newImageMetadata(f, chatRequestString, resp.Created)
+	_, err = saveImageResponse(resp, f, chatContext, metadata)
+	return err
+}
+
+func createDalle2Edit(ctx context.Context, client *openai.Client, f *ImageFlags, mask []byte, prompt string) (openai.ImageResponse, error) {
+	input, err := os.Open(f.InputFiles[0])
+	if err != nil {
+		return openai.ImageResponse{}, fmt.Errorf("unable to read image: %w", err)
+	}
+	defer func(input *os.File) { _ = input.Close() }(input)
+
+	request := openai.ImageEditRequest{
+		Image:          openai.WrapReader(input, filepath.Base(f.InputFiles[0]), "image/png"),
+		Prompt:         prompt,
+		Model:          f.Model,
+		N:              f.NumberImages,
+		Size:           f.Size,
+		ResponseFormat: openai.CreateImageResponseFormatB64JSON,
+	}
+	if mask != nil {
+		request.Mask = openai.WrapReader(bytes.NewReader(mask), "mask.png", "image/png")
+	}
+	return client.CreateEditImage(ctx, request)
+}
+
+// createGptImage1Edit posts the edit request directly to the API at baseURL, as CreateEditImage sends neither the
+// model nor more than one image
+func createGptImage1Edit(ctx context.Context, baseURL string, apikey string, f *ImageFlags, mask []byte, prompt string) (openai.ImageResponse, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, inputFile := range f.InputFiles {
+		data, err := os.ReadFile(inputFile)
+		if err != nil {
+			return openai.ImageResponse{}, fmt.Errorf("unable to read image: %w", err)
+		}
+		if err := writeImagePart(writer, "image[]", filepath.Base(inputFile), data); err != nil {
+			return openai.ImageResponse{}, err
+		}
+	}
+	if mask != nil {
+		if err := writeImagePart(writer, "mask", "mask.png", mask); err != nil {
+			return openai.ImageResponse{}, err
+		}
+	}
+	fields := [][2]string{
+		{"model", f.Model},
+		{"prompt", prompt},
+		{"n", strconv.Itoa(f.NumberImages)},
+		{"size", f.Size},
+		{"quality", f.Quality},
+		{"background", f.Background},
+		{"output_format", f.ImageFormat},
+	}
+	if f.ImageFormat != openai.CreateImageOutputFormatPNG {
+		fields = append(fields, [2]string{"output_compression", strconv.Itoa(f.Compression)})
+	}
+	for _, field := range fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return openai.ImageResponse{}, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return openai.ImageResponse{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(baseURL, "/")+"/images/edits", body)
+	if err != nil {
+		return openai.ImageResponse{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apikey)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return openai.ImageResponse{}, err
+	}
+	defer func(body io.ReadCloser) { _ = body.Close() }(res.Body)
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return openai.ImageResponse{}, fmt.Errorf("error, reading response body: %w", err)
+	}
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+		var errRes openai.ErrorResponse
+		if err := json.Unmarshal(resBody, &errRes); err != nil || errRes.Error == nil {
+			return openai.ImageResponse{}, fmt.Errorf("error, status code: %d, status: %s, body: %s", res.StatusCode, res.Status, resBody)
+		}
+		errRes.Error.HTTPStatus = res.Status
+		errRes.Error.HTTPStatusCode = res.StatusCode
+		return openai.ImageResponse{}, errRes.Error
+	}
+
+	var resp openai.ImageResponse
+	if err := json.Unmarshal(resBody, &resp); err != nil {
+		return openai.ImageResponse{}, fmt.Errorf("error, decoding response body: %w", err)
+	}
+	return resp, nil
+}
+
+// writeImagePart writes an image file part, with its content type, which the API uses to recognise the image format
+func writeImagePart(writer *multipart.Writer, fieldName string, fileName string, data []byte) error {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(fieldName), quoteEscaper.Replace(fileName)))
+	h.Set("Content-Type", http.DetectContentType(data))
+	part, err := writer.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(data)
+	return err
+}
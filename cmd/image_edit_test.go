@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Image Edit", func() {
+	It("should send the edit to the base URL, with the API key", func() {
+		var path, auth string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path, auth = r.URL.Path, r.Header.Get("Authorization")
+			Ω(r.ParseMultipartForm(1 << 20)).To(Succeed())
+			Ω(r.FormValue("model")).To(Equal(openai.CreateImageModelGptImage1))
+			_, _ = w.Write([]byte(`{"created": 1, "data": [{"b64_json": "aW1hZ2U="}]}`))
+		}))
+		defer server.Close()
+
+		var buf bytes.Buffer
+		Ω(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4)))).To(Succeed())
+		inputFile := filepath.Join(GinkgoT().TempDir(), "in.png")
+		Ω(os.WriteFile(inputFile, buf.Bytes(), 0644)).To(Succeed())
+
+		f := NewImageFlags()
+		f.Model = openai.CreateImageModelGptImage1
+		f.InputFiles = []string{inputFile}
+
+		resp, err := createGptImage1Edit(context.Background(), server.URL+"/v1/", "key", f, nil, "make it blue")
+		Ω(err).ToNot(HaveOccurred())
+		Ω(resp.Data).To(HaveLen(1))
+		Ω(path).To(Equal("/v1/images/edits"))
+		Ω(auth).To(Equal("Bearer key"))
+	})
+})
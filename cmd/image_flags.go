@@ -1,28 +1,87 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"image"
+	"net/http"
+	"os"
 
 	"github.com/sashabaranov/go-openai"
 )
 
+const (
+	ImageBackgroundAuto = "auto"
+	ImageModerationAuto = "auto"
+)
+
+const (
+	ImageOutputFormatPNG     = "png"
+	ImageOutputFormatJPEG    = "jpeg"
+	ImageOutputFormatWebpRaw = "webp-raw"
+	ImageOutputFormatKeep    = "keep"
+)
+
+// imageExtensions are the file extensions for each image content type that can be saved
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/webp": ".webp",
+}
+
 type ImageFlags struct {
 	Model             string
 	Size              string
 	Quality           string
 	Style             string
 	NumberImages      int
+	Background        string
+	ImageFormat       string
+	Compression       int
+	Moderation        string
 	OutputPrefix      string
+	OutputFormat      string
+	JpegQuality       int
+	JSON              bool
+	Preview           string
 	CurrentImageCount int
+
+	// PromptsFile, Concurrency, and Retries are used to generate images for each prompt in a file
+	PromptsFile string
+	Concurrency int
+	Retries     int
+
+	// InputFiles, MaskFile, and MaskRect are used by image edit
+	InputFiles []string
+	MaskFile   string
+	MaskRect   string
 }
 
 func NewImageFlags() *ImageFlags {
 	return &ImageFlags{
+		Background:        defaultImageBackground,
+		ImageFormat:       defaultImageFormat,
+		Compression:       defaultImageCompression,
+		Moderation:        defaultImageModeration,
+		OutputFormat:      defaultImageOutputFormat,
+		JpegQuality:       defaultJpegQuality,
+		Preview:           defaultImagePreview,
+		Concurrency:       defaultConcurrency,
+		Retries:           defaultRetries,
 		CurrentImageCount: 1,
 	}
 }
 
 func (f *ImageFlags) ValidateFlags() error {
+	if err := f.ValidateOutputFlags(); err != nil {
+		return err
+	}
+	if f.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+	if f.Retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
 	switch f.Model {
 	case openai.CreateImageModelGptImage1:
 		return f.ValidateGptImage1Flags()
@@ -35,7 +94,184 @@ func (f *ImageFlags) ValidateFlags() error {
 	}
 }
 
+// ValidateOutputFlags validates how images are saved, which applies to every model
+func (f *ImageFlags) ValidateOutputFlags() error {
+	switch f.OutputFormat {
+	case ImageOutputFormatPNG, ImageOutputFormatJPEG, ImageOutputFormatWebpRaw, ImageOutputFormatKeep:
+		// these are fine
+	default:
+		return fmt.Errorf("output-format must be one of png, jpeg, webp-raw, or keep")
+	}
+	if f.JpegQuality < 1 || f.JpegQuality > 100 {
+		return fmt.Errorf("jpeg-quality must be between 1 and 100, inclusive")
+	}
+	switch f.Preview {
+	case ImagePreviewAuto, ImagePreviewInline, ImagePreviewOpen, ImagePreviewNone:
+		// these are fine
+	default:
+		return fmt.Errorf("preview must be one of auto, inline, open, or none")
+	}
+	return nil
+}
+
+// ValidateEditFlags validates the flags for image edit, which is only supported by GPT-Image-1 and DALL-E 2
+func (f *ImageFlags) ValidateEditFlags() error {
+	if err := f.ValidateOutputFlags(); err != nil {
+		return err
+	}
+	if len(f.InputFiles) == 0 {
+		return fmt.Errorf("at least one input image is required")
+	}
+	if f.MaskFile != "" && f.MaskRect != "" {
+		return fmt.Errorf("only one of mask or mask-rect may be given")
+	}
+
+	switch f.Model {
+	case openai.CreateImageModelGptImage1:
+		if err := f.ValidateGptImage1Flags(); err != nil {
+			return err
+		}
+		if f.Moderation != defaultImageModeration {
+			return fmt.Errorf("moderation is not supported for image edit")
+		}
+		if len(f.InputFiles) > maxGptImage1EditInputs {
+			return fmt.Errorf("no more than %d input images may be given, for GPT-Image-1", maxGptImage1EditInputs)
+		}
+		for _, inputFile := range f.InputFiles {
+			contentType, _, err := readImageFileInfo(inputFile, maxGptImage1EditFileSize)
+			if err != nil {
+				return err
+			}
+			switch contentType {
+			case "image/png", "image/jpeg", "image/webp":
+				// these are fine
+			default:
+				return fmt.Errorf("input image must be a PNG, JPEG, or WebP file, for GPT-Image-1: %s", inputFile)
+			}
+		}
+	case openai.CreateImageModelDallE2:
+		if err := f.ValidateDalle2Flags(); err != nil {
+			return err
+		}
+		if len(f.InputFiles) != 1 {
+			return fmt.Errorf("exactly one input image must be given, for DALL-E 2")
+		}
+		contentType, config, err := readImageFileInfo(f.InputFiles[0], maxDalle2EditFileSize)
+		if err != nil {
+			return err
+		}
+		if contentType != "image/png" {
+			return fmt.Errorf("input image must be a PNG file, for DALL-E 2: %s", f.InputFiles[0])
+		}
+		if config.Width != config.Height {
+			return fmt.Errorf("input image must be square, for DALL-E 2: %s is %dx%d", f.InputFiles[0], config.Width, config.Height)
+		}
+	default:
+		return fmt.Errorf("model must be one of 'gpt-image-1' or 'dall-e-2', for image edit")
+	}
+
+	if f.MaskFile != "" {
+		return f.validateMaskFile()
+	}
+	if f.MaskRect != "" {
+		return f.validateMaskRect()
+	}
+	return nil
+}
+
+// validateMaskRect checks the mask rectangle lies within the first input image
+func (f *ImageFlags) validateMaskRect() error {
+	rect, err := parseMaskRect(f.MaskRect)
+	if err != nil {
+		return err
+	}
+	_, inputConfig, err := readImageFileInfo(f.InputFiles[0], maxGptImage1EditFileSize)
+	if err != nil {
+		return err
+	}
+	if !rect.In(image.Rect(0, 0, inputConfig.Width, inputConfig.Height)) {
+		return fmt.Errorf("mask-rect must be within the first input image, %dx%d", inputConfig.Width, inputConfig.Height)
+	}
+	return nil
+}
+
+// ValidateVariationFlags validates the flags for image variations, which are only supported by DALL-E 2
+func (f *ImageFlags) ValidateVariationFlags() error {
+	if err := f.ValidateOutputFlags(); err != nil {
+		return err
+	}
+	if len(f.InputFiles) == 0 {
+		return fmt.Errorf("at least one input image is required")
+	}
+	if f.Model != openai.CreateImageModelDallE2 {
+		return fmt.Errorf("model must be 'dall-e-2', for image variations")
+	}
+	if err := f.ValidateDalle2Flags(); err != nil {
+		return err
+	}
+	for _, inputFile := range f.InputFiles {
+		contentType, _, err := readImageFileInfo(inputFile, maxVariationInputFileSize)
+		if err != nil {
+			return err
+		}
+		switch contentType {
+		case "image/png", "image/jpeg", "image/webp":
+			// these are fine, and converted to PNG before sending
+		default:
+			return fmt.Errorf("input image must be a PNG, JPEG, or WebP file: %s", inputFile)
+		}
+	}
+	return nil
+}
+
+// validateMaskFile checks the mask is a PNG the same size as the first input image, within the model's size limit
+func (f *ImageFlags) validateMaskFile() error {
+	maxMaskFileSize := int64(maxGptImage1EditFileSize)
+	if f.Model == openai.CreateImageModelDallE2 {
+		maxMaskFileSize = maxDalle2EditFileSize
+	}
+	contentType, maskConfig, err := readImageFileInfo(f.MaskFile, maxMaskFileSize)
+	if err != nil {
+		return err
+	}
+	if contentType != "image/png" {
+		return fmt.Errorf("mask must be a PNG file: %s", f.MaskFile)
+	}
+	_, inputConfig, err := readImageFileInfo(f.InputFiles[0], maxGptImage1EditFileSize)
+	if err != nil {
+		return err
+	}
+	if maskConfig.Width != inputConfig.Width || maskConfig.Height != inputConfig.Height {
+		return fmt.Errorf("mask must be the same size as the first input image, %dx%d", inputConfig.Width, inputConfig.Height)
+	}
+	return nil
+}
+
+// readImageFileInfo returns the content type and dimensions of an image file, checking it is no larger than maxSize bytes
+func readImageFileInfo(fileName string, maxSize int64) (string, image.Config, error) {
+	stat, err := os.Stat(fileName)
+	if err != nil {
+		return "", image.Config{}, fmt.Errorf("unable to read image: %w", err)
+	}
+	if stat.Size() > maxSize {
+		return "", image.Config{}, fmt.Errorf("image must be smaller than %dMB: %s", maxSize/(1024*1024), fileName)
+	}
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return "", image.Config{}, fmt.Errorf("unable to read image: %w", err)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", image.Config{}, fmt.Errorf("unable to decode image %s: %w", fileName, err)
+	}
+	return http.DetectContentType(data), config, nil
+}
+
 func (f *ImageFlags) ValidateDalle2Flags() error {
+	if err := f.validateDefaultGptImage1Options("DALL-E 2"); err != nil {
+		return err
+	}
 	if f.NumberImages < 1 || f.NumberImages > 10 {
 		return fmt.Errorf("NumberImages must be between 1 and 10, inclusive")
 	}
@@ -49,6 +285,9 @@ func (f *ImageFlags) ValidateDalle2Flags() error {
 }
 
 func (f *ImageFlags) ValidateDalle3Flags() error {
+	if err := f.validateDefaultGptImage1Options("DALL-E 3"); err != nil {
+		return err
+	}
 	if f.NumberImages != 1 {
 		return fmt.Errorf("NumberImages must be 1, for DALL-E 3")
 	}
@@ -74,8 +313,8 @@ func (f *ImageFlags) ValidateDalle3Flags() error {
 }
 
 func (f *ImageFlags) ValidateGptImage1Flags() error {
-	if f.NumberImages != 1 {
-		return fmt.Errorf("NumberImages must be 1, for GPT-Image-1")
+	if f.NumberImages < 1 || f.NumberImages > 10 {
+		return fmt.Errorf("NumberImages must be between 1 and 10, inclusive, for GPT-Image-1")
 	}
 	switch f.Size {
 	case openai.CreateImageSize1024x1024, openai.CreateImageSize1536x1024, openai.CreateImageSize1024x1536:
@@ -89,6 +328,56 @@ func (f *ImageFlags) ValidateGptImage1Flags() error {
 	default:
 		return fmt.Errorf("quality must be one of low, medium, or high, for GPT-Image-1")
 	}
+	switch f.Background {
+	case ImageBackgroundAuto, openai.CreateImageBackgroundTransparent, openai.CreateImageBackgroundOpaque:
+		// these are fine
+	default:
+		return fmt.Errorf("background must be one of auto, transparent, or opaque, for GPT-Image-1")
+	}
+	switch f.ImageFormat {
+	case openai.CreateImageOutputFormatPNG, openai.CreateImageOutputFormatJPEG, openai.CreateImageOutputFormatWEBP:
+		// these are fine
+	default:
+		return fmt.Errorf("image-format must be one of png, jpeg, or webp, for GPT-Image-1")
+	}
+	if f.Compression < 0 || f.Compression > 100 {
+		return fmt.Errorf("compression must be between 0 and 100, inclusive, for GPT-Image-1")
+	}
+	if f.Compression == 0 {
+		// a compression of 0 is left out of the request, so the server default would be used instead
+		return fmt.Errorf("compression must be at least 1, for GPT-Image-1")
+	}
+	if f.Compression != defaultImageCompression && f.ImageFormat == openai.CreateImageOutputFormatPNG {
+		return fmt.Errorf("compression requires an image-format of jpeg or webp, for GPT-Image-1")
+	}
+	switch f.Moderation {
+	case ImageModerationAuto, openai.CreateImageModerationLow:
+		// these are fine
+	default:
+		return fmt.Errorf("moderation must be one of auto or low, for GPT-Image-1")
+	}
+	if f.Background == openai.CreateImageBackgroundTransparent {
+		if f.ImageFormat == openai.CreateImageOutputFormatJPEG {
+			return fmt.Errorf("a transparent background requires an image-format of png or webp")
+		}
+		if f.OutputFormat == ImageOutputFormatJPEG {
+			return fmt.Errorf("a transparent background can not be saved with an output-format of jpeg")
+		}
+	}
 	// Style is not applicable for GPT-Image-1
 	return nil
 }
+
+// validateDefaultGptImage1Options checks the options only supported by GPT-Image-1 are left as their defaults
+func (f *ImageFlags) validateDefaultGptImage1Options(modelName string) error {
+	if f.Background != defaultImageBackground {
+		return fmt.Errorf("background is only supported by GPT-Image-1, not %s", modelName)
+	}
+	if f.ImageFormat != defaultImageFormat || f.Compression != defaultImageCompression {
+		return fmt.Errorf("image-format and compression are only supported by GPT-Image-1, not %s", modelName)
+	}
+	if f.Moderation != defaultImageModeration {
+		return fmt.Errorf("moderation is only supported by GPT-Image-1, not %s", modelName)
+	}
+	return nil
+}
@@ -1,6 +1,13 @@
 package cmd_test
 
 import (
+	"crypto/rand"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+
 	"github.com/duanemay/chatgpt-cli/cmd"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -157,4 +164,247 @@ var _ = Describe("Image Flags", func() {
 		err = imageFlags.ValidateFlags()
 		Ω(err).Error().ToNot(HaveOccurred())
 	})
+
+	Describe("Edit", func() {
+		var dir string
+		writePNG := func(name string, width int, height int) string {
+			fileName := filepath.Join(dir, name)
+			file, err := os.Create(fileName)
+			Ω(err).ToNot(HaveOccurred())
+			defer func() { _ = file.Close() }()
+			Ω(png.Encode(file, image.NewNRGBA(image.Rect(0, 0, width, height)))).To(Succeed())
+			return fileName
+		}
+		newEditFlags := func(model string, inputFiles ...string) *cmd.ImageFlags {
+			imageFlags := cmd.NewImageFlags()
+			imageFlags.Model = model
+			imageFlags.NumberImages = 1
+			imageFlags.Size = openai.CreateImageSize1024x1024
+			imageFlags.Quality = openai.CreateImageQualityHigh
+			imageFlags.InputFiles = inputFiles
+			return imageFlags
+		}
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+		})
+
+		It("should require an input image", func() {
+			err := newEditFlags(openai.CreateImageModelGptImage1).ValidateEditFlags()
+			Ω(err).To(MatchError(ContainSubstring("at least one input image is required")))
+		})
+
+		It("should not support DALL-E 3", func() {
+			err := newEditFlags(openai.CreateImageModelDallE3, writePNG("in.png", 8, 8)).ValidateEditFlags()
+			Ω(err).To(MatchError(ContainSubstring("model must be one of 'gpt-image-1' or 'dall-e-2', for image edit")))
+		})
+
+		It("should validate inputs for DALL-E 2", func() {
+			square := writePNG("square.png", 8, 8)
+			Ω(newEditFlags(openai.CreateImageModelDallE2, square).ValidateEditFlags()).To(Succeed())
+
+			err := newEditFlags(openai.CreateImageModelDallE2, square, square).ValidateEditFlags()
+			Ω(err).To(MatchError(ContainSubstring("exactly one input image")))
+
+			err = newEditFlags(openai.CreateImageModelDallE2, writePNG("wide.png", 8, 4)).ValidateEditFlags()
+			Ω(err).To(MatchError(ContainSubstring("must be square")))
+
+			jpegFile := filepath.Join(dir, "photo.jpg")
+			file, err := os.Create(jpegFile)
+			Ω(err).ToNot(HaveOccurred())
+			Ω(jpeg.Encode(file, image.NewRGBA(image.Rect(0, 0, 8, 8)), nil)).To(Succeed())
+			Ω(file.Close()).To(Succeed())
+			err = newEditFlags(openai.CreateImageModelDallE2, jpegFile).ValidateEditFlags()
+			Ω(err).To(MatchError(ContainSubstring("must be a PNG file")))
+			Ω(newEditFlags(openai.CreateImageModelGptImage1, jpegFile).ValidateEditFlags()).To(Succeed())
+		})
+
+		It("should allow several inputs for GPT-Image-1", func() {
+			imageFlags := newEditFlags(openai.CreateImageModelGptImage1, writePNG("a.png", 8, 4), writePNG("b.png", 4, 8))
+			Ω(imageFlags.ValidateEditFlags()).To(Succeed())
+
+			imageFlags.Moderation = openai.CreateImageModerationLow
+			Ω(imageFlags.ValidateEditFlags()).To(MatchError(ContainSubstring("moderation is not supported for image edit")))
+			imageFlags.Moderation = cmd.ImageModerationAuto
+
+			imageFlags.InputFiles = append(imageFlags.InputFiles, filepath.Join(dir, "missing.png"))
+			Ω(imageFlags.ValidateEditFlags()).To(MatchError(ContainSubstring("unable to read image")))
+		})
+
+		It("should validate the mask", func() {
+			imageFlags := newEditFlags(openai.CreateImageModelGptImage1, writePNG("in.png", 8, 8))
+			imageFlags.MaskFile = writePNG("mask.png", 8, 8)
+			Ω(imageFlags.ValidateEditFlags()).To(Succeed())
+
+			imageFlags.MaskFile = writePNG("small-mask.png", 4, 4)
+			Ω(imageFlags.ValidateEditFlags()).To(MatchError(ContainSubstring("mask must be the same size")))
+
+			imageFlags.MaskRect = "0,0,2,2"
+			Ω(imageFlags.ValidateEditFlags()).To(MatchError(ContainSubstring("only one of mask or mask-rect")))
+
+			imageFlags.MaskFile = ""
+			Ω(imageFlags.ValidateEditFlags()).To(Succeed())
+
+			imageFlags.MaskRect = "4,4,8,8"
+			Ω(imageFlags.ValidateEditFlags()).To(MatchError(ContainSubstring("mask-rect must be within the first input image")))
+		})
+
+		It("should only limit masks to 4 MB for DALL-E 2", func() {
+			// random pixels don't compress, so the mask is larger than 4 MB
+			noise := image.NewNRGBA(image.Rect(0, 0, 1100, 1100))
+			_, _ = rand.Read(noise.Pix)
+			maskFile := filepath.Join(dir, "noise.png")
+			file, err := os.Create(maskFile)
+			Ω(err).ToNot(HaveOccurred())
+			Ω(png.Encode(file, noise)).To(Succeed())
+			Ω(file.Close()).To(Succeed())
+
+			imageFlags := newEditFlags(openai.CreateImageModelGptImage1, writePNG("in.png", 1100, 1100))
+			imageFlags.MaskFile = maskFile
+			Ω(imageFlags.ValidateEditFlags()).To(Succeed())
+
+			imageFlags.Model = openai.CreateImageModelDallE2
+			imageFlags.Quality = ""
+			Ω(imageFlags.ValidateEditFlags()).To(MatchError(ContainSubstring("image must be smaller than 4MB")))
+		})
+	})
+
+	Describe("Variations", func() {
+		It("should only support DALL-E 2", func() {
+			dir := GinkgoT().TempDir()
+			fileName := filepath.Join(dir, "photo.jpg")
+			file, err := os.Create(fileName)
+			Ω(err).ToNot(HaveOccurred())
+			Ω(jpeg.Encode(file, image.NewRGBA(image.Rect(0, 0, 20, 12)), nil)).To(Succeed())
+			Ω(file.Close()).To(Succeed())
+
+			imageFlags := cmd.NewImageFlags()
+			imageFlags.Model = openai.CreateImageModelDallE2
+			imageFlags.NumberImages = 4
+			imageFlags.Size = openai.CreateImageSize512x512
+			Ω(imageFlags.ValidateVariationFlags()).To(MatchError(ContainSubstring("at least one input image is required")))
+
+			imageFlags.InputFiles = []string{fileName}
+			Ω(imageFlags.ValidateVariationFlags()).To(Succeed())
+
+			imageFlags.Size = openai.CreateImageSize1536x1024
+			Ω(imageFlags.ValidateVariationFlags()).To(MatchError(ContainSubstring("size must be one of")))
+
+			imageFlags.Model = openai.CreateImageModelGptImage1
+			Ω(imageFlags.ValidateVariationFlags()).To(MatchError(ContainSubstring("model must be 'dall-e-2', for image variations")))
+		})
+	})
+
+	It("should validate Output Format and JPEG Quality", func() {
+		imageFlags := cmd.NewImageFlags()
+		Ω(imageFlags.ValidateOutputFlags()).To(Succeed())
+
+		for _, format := range []string{cmd.ImageOutputFormatJPEG, cmd.ImageOutputFormatWebpRaw, cmd.ImageOutputFormatKeep} {
+			imageFlags.OutputFormat = format
+			Ω(imageFlags.ValidateOutputFlags()).To(Succeed())
+		}
+
+		imageFlags.OutputFormat = "gif"
+		Ω(imageFlags.ValidateOutputFlags()).To(MatchError(ContainSubstring("output-format must be one of")))
+
+		imageFlags.OutputFormat = cmd.ImageOutputFormatJPEG
+		imageFlags.JpegQuality = 0
+		Ω(imageFlags.ValidateOutputFlags()).To(MatchError(ContainSubstring("jpeg-quality must be between 1 and 100")))
+		imageFlags.JpegQuality = 101
+		Ω(imageFlags.ValidateOutputFlags()).To(MatchError(ContainSubstring("jpeg-quality must be between 1 and 100")))
+
+		imageFlags.JpegQuality = 80
+		for _, preview := range []string{cmd.ImagePreviewAuto, cmd.ImagePreviewInline, cmd.ImagePreviewOpen, cmd.ImagePreviewNone} {
+			imageFlags.Preview = preview
+			Ω(imageFlags.ValidateOutputFlags()).To(Succeed())
+		}
+		imageFlags.Preview = "browser"
+		Ω(imageFlags.ValidateOutputFlags()).To(MatchError(ContainSubstring("preview must be one of")))
+	})
+
+	It("should validate Concurrency and Retries", func() {
+		imageFlags := cmd.NewImageFlags()
+		imageFlags.Model = openai.CreateImageModelDallE2
+		imageFlags.NumberImages = 1
+		imageFlags.Size = openai.CreateImageSize256x256
+		Ω(imageFlags.ValidateFlags()).To(Succeed())
+
+		imageFlags.Concurrency = 0
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("concurrency must be at least 1")))
+
+		imageFlags.Concurrency = 1
+		imageFlags.Retries = -1
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("retries must not be negative")))
+	})
+
+	It("should validate Background, Image Format, Compression, and Moderation for GPT Image 1", func() {
+		imageFlags := cmd.NewImageFlags()
+		imageFlags.Model = openai.CreateImageModelGptImage1
+		imageFlags.NumberImages = 4
+		imageFlags.Size = openai.CreateImageSize1024x1024
+		imageFlags.Quality = openai.CreateImageQualityMedium
+		Ω(imageFlags.ValidateFlags()).To(Succeed())
+
+		imageFlags.NumberImages = 11
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("NumberImages must be between 1 and 10, inclusive, for GPT-Image-1")))
+		imageFlags.NumberImages = 1
+
+		imageFlags.Background = "clear"
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("background must be one of")))
+		imageFlags.Background = openai.CreateImageBackgroundTransparent
+		Ω(imageFlags.ValidateFlags()).To(Succeed())
+
+		imageFlags.ImageFormat = openai.CreateImageOutputFormatJPEG
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("a transparent background requires an image-format of png or webp")))
+		imageFlags.ImageFormat = openai.CreateImageOutputFormatWEBP
+		Ω(imageFlags.ValidateFlags()).To(Succeed())
+		imageFlags.OutputFormat = cmd.ImageOutputFormatJPEG
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("can not be saved with an output-format of jpeg")))
+		imageFlags.OutputFormat = cmd.ImageOutputFormatKeep
+		Ω(imageFlags.ValidateFlags()).To(Succeed())
+
+		imageFlags.ImageFormat = "gif"
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("image-format must be one of")))
+
+		imageFlags.ImageFormat = openai.CreateImageOutputFormatWEBP
+		imageFlags.Compression = 101
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("compression must be between 0 and 100")))
+		imageFlags.Compression = 0
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("compression must be at least 1")))
+		imageFlags.Compression = 50
+		Ω(imageFlags.ValidateFlags()).To(Succeed())
+		imageFlags.ImageFormat = openai.CreateImageOutputFormatPNG
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("compression requires an image-format of jpeg or webp")))
+		imageFlags.Compression = 100
+
+		imageFlags.Moderation = "high"
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("moderation must be one of")))
+		imageFlags.Moderation = openai.CreateImageModerationLow
+		Ω(imageFlags.ValidateFlags()).To(Succeed())
+	})
+
+	It("should reject GPT Image 1 options for DALL-E", func() {
+		imageFlags := cmd.NewImageFlags()
+		imageFlags.Model = openai.CreateImageModelDallE2
+		imageFlags.NumberImages = 1
+		imageFlags.Size = openai.CreateImageSize512x512
+		Ω(imageFlags.ValidateFlags()).To(Succeed())
+
+		imageFlags.Background = openai.CreateImageBackgroundOpaque
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("background is only supported by GPT-Image-1, not DALL-E 2")))
+		imageFlags.Background = cmd.ImageBackgroundAuto
+
+		imageFlags.Model = openai.CreateImageModelDallE3
+		imageFlags.Size = openai.CreateImageSize1024x1024
+		imageFlags.Quality = openai.CreateImageQualityHD
+		imageFlags.Style = openai.CreateImageStyleNatural
+		Ω(imageFlags.ValidateFlags()).To(Succeed())
+
+		imageFlags.ImageFormat = openai.CreateImageOutputFormatWEBP
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("image-format and compression are only supported by GPT-Image-1, not DALL-E 3")))
+		imageFlags.ImageFormat = openai.CreateImageOutputFormatPNG
+
+		imageFlags.Moderation = openai.CreateImageModerationLow
+		Ω(imageFlags.ValidateFlags()).To(MatchError(ContainSubstring("moderation is only supported by GPT-Image-1, not DALL-E 3")))
+	})
 })
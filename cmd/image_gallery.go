@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// imageGalleryFileName is the gallery written beside the images from a prompts file
+	imageGalleryFileName = "index.html"
+	// imageThumbnailDir is the directory beside the gallery that its thumbnails are written to
+	imageThumbnailDir = "thumbnails"
+	// imageThumbnailSide is the longest side of a thumbnail, twice the size it is shown, for high density screens
+	imageThumbnailSide = 512
+)
+
+var imageGalleryTemplate = template.Must(template.New("gallery").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; background: #fafafa; }
+.item { display: flex; gap: 1.5em; padding: 1em 0; border-bottom: 1px solid #ddd; }
+.images { display: flex; flex-wrap: wrap; gap: 0.5em; }
+.images img { width: 256px; height: auto; border-radius: 4px; }
+.details { max-width: 50em; }
+.params { color: #666; font-size: 0.9em; }
+.error { color: #b00020; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Items}}
+<div class="item">
+  <div class="images">
+  {{range .Images}}<a href="{{.Path}}"><img src="{{.Thumbnail}}" alt="" loading="lazy"></a>
+  {{end}}
+  </div>
+  <div class="details">
+    <p><strong>Prompt:</strong> {{.Prompt}}</p>
+    {{range .RevisedPrompts}}<p><strong>Revised prompt:</strong> {{.}}</p>
+    {{end}}
+    <p class="params">model: {{.Model}}, size: {{.Size}}{{if .Quality}}, quality: {{.Quality}}{{end}}{{if .Style}}, style: {{.Style}}{{end}}</p>
+    {{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+  </div>
+</div>
+{{end}}
+</body>
+</html>
+`))
+
+type imageGallery struct {
+	Title string
+	Items []imageGalleryItem
+}
+
+type imageGalleryItem struct {
+	Prompt         string
+	RevisedPrompts []string
+	Images         []imageGalleryImage
+	Model          string
+	Size           string
+	Quality        string
+	Style          string
+	Error          string
+}
+
+type imageGalleryImage struct {
+	Path      string
+	Thumbnail string
+}
+
+// writeImageGallery writes an HTML page, in the output directory, showing each prompt's images and parameters
+func writeImageGallery(f *ImageFlags, items []*imageBatchItem) (string, error) {
+	dir := filepath.Dir(f.OutputPrefix)
+	gallery := imageGallery{Title: filepath.Base(f.OutputPrefix)}
+	for _, item := range items {
+		metadata := newImageMetadata(item.Flags, item.Prompt, 0)
+		galleryItem := imageGalleryItem{
+			Prompt:  item.Prompt,
+			Model:   metadata.Model,
+			Size:    metadata.Size,
+			Quality: metadata.Quality,
+			Style:   metadata.Style,
+		}
+		if item.Err != nil {
+			galleryItem.Error = item.Err.Error()
+		}
+		for _, record := range item.Result.Images {
+			path, err := filepath.Rel(dir, record.File)
+			if err != nil {
+				path = record.File
+			}
+			thumbnail, err := writeImageThumbnail(dir, record.File)
+			if err != nil {
+				// the gallery can still show the full size image
+				_, _ = fmt.Fprintf(os.Stderr, "%s\n", ErrorFmt.Sprintf("unable to write thumbnail of %s: %v", record.File, err))
+				thumbnail = path
+			}
+			galleryItem.Images = append(galleryItem.Images, imageGalleryImage{
+				Path:      filepath.ToSlash(path),
+				Thumbnail: filepath.ToSlash(thumbnail),
+			})
+			if record.RevisedPrompt != "" {
+				galleryItem.RevisedPrompts = append(galleryItem.RevisedPrompts, record.RevisedPrompt)
+			}
+		}
+		gallery.Items = append(gallery.Items, galleryItem)
+	}
+
+	fileName := filepath.Join(dir, imageGalleryFileName)
+	file, err := os.Create(fileName)
+	if err != nil {
+		return "", fmt.Errorf("gallery write error: %w", err)
+	}
+	defer func(file *os.File) { _ = file.Close() }(file)
+
+	if err := imageGalleryTemplate.Execute(file, gallery); err != nil {
+		return "", fmt.Errorf("gallery write error: %w", err)
+	}
+	return fileName, nil
+}
+
+// writeImageThumbnail writes a copy of an image, scaled to fit imageThumbnailSide, to the thumbnails directory,
+// and returns its path from the gallery directory. Images that already fit are used as they are.
+func writeImageThumbnail(dir string, file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	contentType := http.DetectContentType(data)
+	img, err := decodeImage(data, contentType)
+	if err != nil {
+		return "", err
+	}
+
+	bounds := img.Bounds()
+	if max(bounds.Dx(), bounds.Dy()) <= imageThumbnailSide {
+		return filepath.Rel(dir, file)
+	}
+	scale := float64(imageThumbnailSide) / float64(max(bounds.Dx(), bounds.Dy()))
+	thumbnail := image.NewRGBA(image.Rect(0, 0, max(int(float64(bounds.Dx())*scale+0.5), 1), max(int(float64(bounds.Dy())*scale+0.5), 1)))
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), img, bounds, draw.Src, nil)
+
+	// photos stay JPEG, anything that might have transparency becomes PNG
+	var buf bytes.Buffer
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if contentType == "image/jpeg" {
+		name += ".jpg"
+		err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: downscaledJpegQuality})
+	} else {
+		name += ".png"
+		err = png.Encode(&buf, thumbnail)
+	}
+	if err != nil {
+		return "", fmt.Errorf("image encode error: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, imageThumbnailDir), 0755); err != nil {
+		return "", fmt.Errorf("unable to create directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, imageThumbnailDir, name), buf.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	return filepath.Join(imageThumbnailDir, name), nil
+}
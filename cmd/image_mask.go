@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+// parseMaskRect parses a rectangle given as x,y,w,h in pixels
+func parseMaskRect(spec string) (image.Rectangle, error) {
+	parts := strings.Split(spec, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, fmt.Errorf("mask-rect must be given as x,y,w,h: %s", spec)
+	}
+	var values [4]int
+	for i, part := range parts {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || value < 0 {
+			return image.Rectangle{}, fmt.Errorf("mask-rect values must be whole numbers, zero or more: %s", spec)
+		}
+		values[i] = value
+	}
+	if values[2] == 0 || values[3] == 0 {
+		return image.Rectangle{}, fmt.Errorf("mask-rect width and height must be more than zero: %s", spec)
+	}
+	return image.Rect(values[0], values[1], values[0]+values[2], values[1]+values[3]), nil
+}
+
+// createRectMask creates a PNG mask of the given size, that is opaque except for the fully transparent rectangle to edit
+func createRectMask(width int, height int, rect image.Rectangle) ([]byte, error) {
+	mask := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(mask, mask.Bounds(), image.NewUniform(color.NRGBA{A: 0xff}), image.Point{}, draw.Src)
+	draw.Draw(mask, rect, image.Transparent, image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, mask); err != nil {
+		return nil, fmt.Errorf("PNG encode error: %w", err)
+	}
+	return buf.Bytes(), nil
+}
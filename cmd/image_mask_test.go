@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Image Mask", func() {
+	It("should parse a rectangle", func() {
+		Ω(parseMaskRect("10,20,30,40")).To(Equal(image.Rect(10, 20, 40, 60)))
+		Ω(parseMaskRect(" 0, 0, 1, 1 ")).To(Equal(image.Rect(0, 0, 1, 1)))
+	})
+
+	It("should reject invalid rectangles", func() {
+		for _, spec := range []string{"", "1,2,3", "1,2,3,4,5", "a,2,3,4", "-1,2,3,4", "1,2,0,4"} {
+			_, err := parseMaskRect(spec)
+			Ω(err).To(HaveOccurred(), spec)
+		}
+	})
+
+	It("should create a mask transparent only within the rectangle", func() {
+		data, err := createRectMask(8, 6, image.Rect(2, 1, 5, 3))
+		Ω(err).ToNot(HaveOccurred())
+
+		mask, err := png.Decode(bytes.NewReader(data))
+		Ω(err).ToNot(HaveOccurred())
+		Ω(mask.Bounds()).To(Equal(image.Rect(0, 0, 8, 6)))
+
+		alpha := func(x, y int) uint32 {
+			_, _, _, a := mask.At(x, y).RGBA()
+			return a
+		}
+		Ω(alpha(2, 1)).To(BeZero())
+		Ω(alpha(4, 2)).To(BeZero())
+		Ω(alpha(5, 2)).To(Equal(uint32(0xffff)))
+		Ω(alpha(0, 0)).To(Equal(uint32(0xffff)))
+		Ω(alpha(7, 5)).To(Equal(uint32(0xffff)))
+	})
+})
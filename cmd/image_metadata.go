@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// pngHeaderSize is the size of the PNG signature and IHDR chunk, which must come first
+const pngHeaderSize = 8 + 4 + 4 + 13 + 4
+
+// ImageMetadata records how an image was made. It is saved in PNG text chunks, or a JSON file beside other formats.
+type ImageMetadata struct {
+	Prompt        string    `json:"prompt,omitempty"`
+	RevisedPrompt string    `json:"revised_prompt,omitempty"`
+	Inputs        []string  `json:"inputs,omitempty"`
+	Model         string    `json:"model"`
+	Size          string    `json:"size"`
+	Quality       string    `json:"quality,omitempty"`
+	Style         string    `json:"style,omitempty"`
+	Background    string    `json:"background,omitempty"`
+	Created       time.Time `json:"created"`
+}
+
+// newImageMetadata records the flags that apply to the model, and the time the image was created
+func newImageMetadata(f *ImageFlags, prompt string, created int64) ImageMetadata {
+	metadata := ImageMetadata{
+		Prompt:  prompt,
+		Inputs:  f.InputFiles,
+		Model:   f.Model,
+		Size:    f.Size,
+		Created: time.Now().UTC(),
+	}
+	if created != 0 {
+		metadata.Created = time.Unix(created, 0).UTC()
+	}
+	if f.Model != openai.CreateImageModelDallE2 {
+		metadata.Quality = f.Quality
+	}
+	if f.Model == openai.CreateImageModelGptImage1 {
+		metadata.Background = f.Background
+	}
+	if f.Model == openai.CreateImageModelDallE3 {
+		metadata.Style = f.Style
+	}
+	return metadata
+}
+
+// textEntries returns the metadata as PNG text keywords and values, skipping empty values
+func (m ImageMetadata) textEntries() [][2]string {
+	entries := [][2]string{
+		{"Software", "chatgpt-cli v" + version},
+		{"Creation Time", m.Created.Format(time.RFC3339)},
+		{"prompt", m.Prompt},
+		{"revised_prompt", m.RevisedPrompt},
+		{"inputs", strings.Join(m.Inputs, ", ")},
+		{"model", m.Model},
+		{"size", m.Size},
+		{"quality", m.Quality},
+		{"style", m.Style},
+		{"background", m.Background},
+	}
+	var result [][2]string
+	for _, entry := range entries {
+		if entry[1] != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// addPNGMetadata inserts the metadata into PNG data, as a tEXt chunk for each value,
+// or an iTXt chunk for values that are not plain ASCII
+func addPNGMetadata(data []byte, metadata ImageMetadata) ([]byte, error) {
+	if len(data) < pngHeaderSize || string(data[12:16]) != "IHDR" {
+		return nil, fmt.Errorf("PNG metadata error: missing IHDR chunk")
+	}
+
+	var buf bytes.Buffer
+	buf.Write(data[:pngHeaderSize])
+	for _, entry := range metadata.textEntries() {
+		if isASCII(entry[1]) {
+			writePNGChunk(&buf, "tEXt", []byte(entry[0]+"\x00"+entry[1]))
+		} else {
+			// keyword, no compression, no language tag, and no translated keyword
+			writePNGChunk(&buf, "iTXt", []byte(entry[0]+"\x00\x00\x00\x00\x00"+entry[1]))
+		}
+	}
+	buf.Write(data[pngHeaderSize:])
+	return buf.Bytes(), nil
+}
+
+func writePNGChunk(buf *bytes.Buffer, chunkType string, chunkData []byte) {
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(chunkData)))
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write([]byte(chunkType))
+	_, _ = crc.Write(chunkData)
+	buf.WriteString(chunkType)
+	buf.Write(chunkData)
+	_ = binary.Write(buf, binary.BigEndian, crc.Sum32())
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// writeImageMetadataFile writes the metadata as JSON, beside the image file, with the same name and a .json extension
+func writeImageMetadataFile(imageFileName string, metadata ImageMetadata) (string, error) {
+	fileName := strings.TrimSuffix(imageFileName, filepath.Ext(imageFileName)) + ".json"
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("metadata encode error: %w", err)
+	}
+	if err := os.WriteFile(fileName, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("file write error: %w", err)
+	}
+	return fileName, nil
+}
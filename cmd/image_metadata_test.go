@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Image Metadata", func() {
+	encode := func(format string) []byte {
+		var buf bytes.Buffer
+		img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		if format == "jpeg" {
+			Ω(jpeg.Encode(&buf, img, nil)).To(Succeed())
+		} else {
+			Ω(png.Encode(&buf, img)).To(Succeed())
+		}
+		return buf.Bytes()
+	}
+	metadata := ImageMetadata{
+		Prompt:        "a monkey on a high wire",
+		RevisedPrompt: "a monkey in a banana costume, on a high wire – above a circus",
+		Model:         openai.CreateImageModelDallE3,
+		Size:          openai.CreateImageSize1024x1024,
+		Created:       time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	It("should only record quality and style for the models that use them", func() {
+		f := NewImageFlags()
+		f.Model = openai.CreateImageModelDallE2
+		f.Quality = openai.CreateImageQualityHD
+		f.Style = openai.CreateImageStyleVivid
+		m := newImageMetadata(f, "prompt", 1767323045)
+		Ω(m.Quality).To(BeEmpty())
+		Ω(m.Style).To(BeEmpty())
+		Ω(m.Created).To(Equal(time.Unix(1767323045, 0).UTC()))
+
+		f.Model = openai.CreateImageModelDallE3
+		m = newImageMetadata(f, "prompt", 0)
+		Ω(m.Quality).To(Equal(openai.CreateImageQualityHD))
+		Ω(m.Style).To(Equal(openai.CreateImageStyleVivid))
+	})
+
+	It("should add text chunks to a PNG that still decodes", func() {
+		data, err := addPNGMetadata(encode("png"), metadata)
+		Ω(err).ToNot(HaveOccurred())
+
+		_, err = png.Decode(bytes.NewReader(data))
+		Ω(err).ToNot(HaveOccurred())
+		Ω(data).To(ContainSubstring("tEXtprompt\x00a monkey on a high wire"))
+		Ω(data).To(ContainSubstring("iTXtrevised_prompt\x00\x00\x00\x00\x00a monkey in a banana costume"))
+		Ω(data).To(ContainSubstring("tEXtCreation Time\x002026-01-02T03:04:05Z"))
+		Ω(data).ToNot(ContainSubstring("style"))
+	})
+
+	It("should reject data that is not a PNG", func() {
+		_, err := addPNGMetadata(encode("jpeg"), metadata)
+		Ω(err).To(MatchError(ContainSubstring("missing IHDR chunk")))
+	})
+
+	It("should write a JSON file beside the image", func() {
+		imageFile := filepath.Join(GinkgoT().TempDir(), "image-01.jpg")
+		fileName, err := writeImageMetadataFile(imageFile, metadata)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(fileName).To(HaveSuffix("image-01.json"))
+
+		data, err := os.ReadFile(fileName)
+		Ω(err).ToNot(HaveOccurred())
+		var read ImageMetadata
+		Ω(json.Unmarshal(data, &read)).To(Succeed())
+		Ω(read).To(Equal(metadata))
+	})
+
+	It("should convert images to the output format", func() {
+		f := NewImageFlags()
+		pngData := encode("png")
+		jpegData := encode("jpeg")
+
+		output, extension, err := convertImage(pngData, f)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(extension).To(Equal(".png"))
+		Ω(output).To(Equal(pngData))
+
+		output, extension, err = convertImage(jpegData, f)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(extension).To(Equal(".png"))
+		_, err = png.Decode(bytes.NewReader(output))
+		Ω(err).ToNot(HaveOccurred())
+
+		f.OutputFormat = ImageOutputFormatJPEG
+		output, extension, err = convertImage(pngData, f)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(extension).To(Equal(".jpg"))
+		_, err = jpeg.Decode(bytes.NewReader(output))
+		Ω(err).ToNot(HaveOccurred())
+
+		output, _, err = convertImage(jpegData, f)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(output).To(Equal(jpegData))
+
+		f.OutputFormat = ImageOutputFormatKeep
+		output, extension, err = convertImage(jpegData, f)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(extension).To(Equal(".jpg"))
+		Ω(output).To(Equal(jpegData))
+
+		f.OutputFormat = ImageOutputFormatWebpRaw
+		_, _, err = convertImage(pngData, f)
+		Ω(err).To(MatchError(ContainSubstring("webp-raw requires WebP image data")))
+	})
+})
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// imageManifestFileName is the file, beside the images, that a line is appended to for each image saved
+const imageManifestFileName = "manifest.jsonl"
+
+// ImageRecord describes a saved image, and how it was made
+type ImageRecord struct {
+	File         string `json:"file"`
+	MetadataFile string `json:"metadata_file,omitempty"`
+	Bytes        int    `json:"bytes"`
+	ImageMetadata
+}
+
+// ImageResult lists the images saved from a response, printed with --json
+type ImageResult struct {
+	Images []ImageRecord              `json:"images"`
+	Usage  *openai.ImageResponseUsage `json:"usage,omitempty"`
+}
+
+// saveImageResponse saves each image in the response, records them in the manifest, and prints them as JSON if requested
+func saveImageResponse(resp openai.ImageResponse, f *ImageFlags, chatContext *ChatContext, metadata ImageMetadata) (ImageResult, error) {
+	result := ImageResult{Images: []ImageRecord{}}
+	if resp.Usage.TotalTokens > 0 {
+		result.Usage = &resp.Usage
+	}
+
+	for _, data := range resp.Data {
+		record, err := processImageData(data, f, chatContext, metadata)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			continue
+		}
+		result.Images = append(result.Images, record)
+	}
+
+	if err := appendImageManifest(f, result.Images); err != nil {
+		return result, err
+	}
+	if f.JSON {
+		output, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return result, fmt.Errorf("JSON encode error: %w", err)
+		}
+		fmt.Printf("%s\n", output)
+	}
+	return result, nil
+}
+
+// appendImageManifest appends a line for each image to the manifest in the output directory
+func appendImageManifest(f *ImageFlags, records []ImageRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	fileName := filepath.Join(filepath.Dir(f.OutputPrefix), imageManifestFileName)
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("manifest write error: %w", err)
+	}
+	defer func(file *os.File) { _ = file.Close() }(file)
+
+	encoder := json.NewEncoder(file)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("manifest write error: %w", err)
+		}
+	}
+	return nil
+}
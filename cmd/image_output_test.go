@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Image Output", func() {
+	var f *ImageFlags
+	var dir string
+	var response openai.ImageResponse
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		f = NewImageFlags()
+		f.Model = openai.CreateImageModelDallE3
+		f.Size = openai.CreateImageSize1024x1024
+		f.OutputPrefix = filepath.Join(dir, "monkey")
+
+		var buf bytes.Buffer
+		Ω(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4)))).To(Succeed())
+		response = openai.ImageResponse{
+			Created: 1767323045,
+			Data: []openai.ImageResponseDataInner{
+				{B64JSON: base64.StdEncoding.EncodeToString(buf.Bytes()), RevisedPrompt: "a monkey in a banana costume"},
+				{B64JSON: "not an image"},
+			},
+		}
+	})
+
+	readManifest := func() []ImageRecord {
+		file, err := os.Open(filepath.Join(dir, imageManifestFileName))
+		Ω(err).ToNot(HaveOccurred())
+		defer func() { _ = file.Close() }()
+
+		var records []ImageRecord
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var record ImageRecord
+			Ω(json.Unmarshal(scanner.Bytes(), &record)).To(Succeed())
+			records = append(records, record)
+		}
+		return records
+	}
+
+	It("should save images, and append them to the manifest", func() {
+		metadata := newImageMetadata(f, "a monkey", response.Created)
+		_, err := saveImageResponse(response, f, NewChatContext(), metadata)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(filepath.Join(dir, "monkey-01.png")).To(BeAnExistingFile())
+
+		records := readManifest()
+		Ω(records).To(HaveLen(1))
+		Ω(records[0].File).To(Equal(filepath.Join(dir, "monkey-01.png")))
+		Ω(records[0].Prompt).To(Equal("a monkey"))
+		Ω(records[0].RevisedPrompt).To(Equal("a monkey in a banana costume"))
+		Ω(records[0].Size).To(Equal(openai.CreateImageSize1024x1024))
+		Ω(records[0].Bytes).To(BeNumerically(">", 0))
+
+		_, err = saveImageResponse(response, f, NewChatContext(), metadata)
+		Ω(err).ToNot(HaveOccurred())
+		records = readManifest()
+		Ω(records).To(HaveLen(2))
+		Ω(records[1].File).To(Equal(filepath.Join(dir, "monkey-02.png")))
+	})
+
+	It("should record the metadata file for formats other than PNG", func() {
+		f.OutputFormat = ImageOutputFormatJPEG
+		_, err := saveImageResponse(response, f, NewChatContext(), newImageMetadata(f, "a monkey", 0))
+		Ω(err).ToNot(HaveOccurred())
+
+		records := readManifest()
+		Ω(records).To(HaveLen(1))
+		Ω(records[0].File).To(Equal(filepath.Join(dir, "monkey-01.jpg")))
+		Ω(records[0].MetadataFile).To(Equal(filepath.Join(dir, "monkey-01.json")))
+		Ω(records[0].MetadataFile).To(BeAnExistingFile())
+	})
+})
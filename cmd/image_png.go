@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+)
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// encodeRGBAPNG exists because png.Encode writes opaque images as RGB, and the API rejects images without alpha
+func encodeRGBAPNG(buf *bytes.Buffer, img *image.NRGBA) error {
+	bounds := img.Bounds()
+	header := make([]byte, 0, 13)
+	header = binary.BigEndian.AppendUint32(header, uint32(bounds.Dx()))
+	header = binary.BigEndian.AppendUint32(header, uint32(bounds.Dy()))
+	// bit depth 8, color type 6 (RGBA), then the default compression, filtering, and no interlacing
+	header = append(header, 8, 6, 0, 0, 0)
+
+	var data bytes.Buffer
+	zw := zlib.NewWriter(&data)
+	rowLength := bounds.Dx() * 4
+	previous := make([]byte, rowLength)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		start := img.PixOffset(bounds.Min.X, y)
+		row := img.Pix[start : start+rowLength]
+		if _, err := zw.Write(filterPNGRow(row, previous)); err != nil {
+			return err
+		}
+		previous = row
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	buf.Write(pngSignature)
+	writePNGChunk(buf, "IHDR", header)
+	writePNGChunk(buf, "IDAT", data.Bytes())
+	writePNGChunk(buf, "IEND", nil)
+	return nil
+}
+
+// filterPNGRow returns the row, preceded by its filter type, with the filter that leaves the smallest differences,
+// which usually compresses best
+func filterPNGRow(row []byte, previous []byte) []byte {
+	const bytesPerPixel = 4
+	best, bestSum := []byte(nil), -1
+	for filter := byte(0); filter <= 4; filter++ {
+		filtered := make([]byte, len(row)+1)
+		filtered[0] = filter
+		sum := 0
+		for i, value := range row {
+			var left, up, upLeft byte
+			if i >= bytesPerPixel {
+				left, upLeft = row[i-bytesPerPixel], previous[i-bytesPerPixel]
+			}
+			up = previous[i]
+			switch filter {
+			case 1:
+				value -= left
+			case 2:
+				value -= up
+			case 3:
+				value -= byte((int(left) + int(up)) / 2)
+			case 4:
+				value -= paethPredictor(left, up, upLeft)
+			}
+			filtered[i+1] = value
+			sum += int(min(value, -value))
+		}
+		if bestSum < 0 || sum < bestSum {
+			best, bestSum = filtered, sum
+		}
+	}
+	return best
+}
+
+// paethPredictor returns whichever of the neighbouring bytes is closest to left + up - upLeft
+func paethPredictor(left, up, upLeft byte) byte {
+	abs := func(x int) int {
+		if x < 0 {
+			return -x
+		}
+		return x
+	}
+	estimate := int(left) + int(up) - int(upLeft)
+	distanceLeft := abs(estimate - int(left))
+	distanceUp := abs(estimate - int(up))
+	distanceUpLeft := abs(estimate - int(upLeft))
+	switch {
+	case distanceLeft <= distanceUp && distanceLeft <= distanceUpLeft:
+		return left
+	case distanceUp <= distanceUpLeft:
+		return up
+	default:
+		return upLeft
+	}
+}
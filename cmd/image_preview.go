@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	os2 "github.com/duanemay/chatgpt-cli/pkg/os"
+	"github.com/duanemay/chatgpt-cli/pkg/preview"
+	"golang.org/x/term"
+)
+
+const (
+	ImagePreviewAuto   = "auto"
+	ImagePreviewInline = "inline"
+	ImagePreviewOpen   = "open"
+	ImagePreviewNone   = "none"
+
+	// maxPreviewColumns limits the width of inline previews, in terminal columns
+	maxPreviewColumns = 60
+)
+
+// previewImage shows the saved image, inline in the terminal, or by opening it with the default application.
+// Preview problems are reported, but never stop the session, as the image has already been saved.
+func previewImage(f *ImageFlags, chatContext *ChatContext, fileName string, imgBytes []byte) {
+	mode := f.Preview
+	if mode == ImagePreviewAuto {
+		if !chatContext.InteractiveSession || !term.IsTerminal(int(os.Stdout.Fd())) {
+			return
+		}
+		mode = autoPreviewMode(preview.DetectProtocol(os.Getenv), os2.HasDisplay())
+	}
+
+	switch mode {
+	case ImagePreviewOpen:
+		if err := os2.OpenBrowser(fileName); err != nil {
+			printPreviewError(err)
+		}
+	case ImagePreviewInline:
+		if err := renderInlinePreview(imgBytes); err != nil {
+			printPreviewError(err)
+		}
+	}
+}
+
+// autoPreviewMode shows images inline when the terminal supports graphics, otherwise opens them when there is
+// a desktop to show them on, and falls back to drawing them inline with half blocks
+func autoPreviewMode(protocol preview.Protocol, hasDisplay bool) string {
+	if protocol == preview.HalfBlock && hasDisplay {
+		return ImagePreviewOpen
+	}
+	return ImagePreviewInline
+}
+
+func renderInlinePreview(imgBytes []byte) error {
+	img, err := decodeImage(imgBytes, http.DetectContentType(imgBytes))
+	if err != nil {
+		return err
+	}
+	columns := maxPreviewColumns
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		columns = min(width, maxPreviewColumns)
+	}
+	return preview.Render(os.Stdout, img, preview.DetectProtocol(os.Getenv), columns)
+}
+
+func printPreviewError(err error) {
+	_, _ = fmt.Fprintf(os.Stderr, "%s\n", ErrorFmt.Sprintf("unable to preview image: %v", err))
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/duanemay/chatgpt-cli/pkg/preview"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Image Preview", func() {
+	It("should show images inline when the terminal supports graphics", func() {
+		Ω(autoPreviewMode(preview.Kitty, true)).To(Equal(ImagePreviewInline))
+		Ω(autoPreviewMode(preview.Sixel, false)).To(Equal(ImagePreviewInline))
+	})
+
+	It("should open images when there is a desktop, and draw half blocks otherwise", func() {
+		Ω(autoPreviewMode(preview.HalfBlock, true)).To(Equal(ImagePreviewOpen))
+		Ω(autoPreviewMode(preview.HalfBlock, false)).To(Equal(ImagePreviewInline))
+	})
+
+	It("should never stop on a preview that fails", func() {
+		f := NewImageFlags()
+		f.Preview = ImagePreviewInline
+		Ω(func() { previewImage(f, NewChatContext(), "missing.png", []byte("not an image")) }).ToNot(Panic())
+	})
+})
@@ -21,4 +21,16 @@ var _ = Describe("Image Command", func() {
 		Ω(rootCmd.Commands()).To(ContainElement(HaveField("Use", commandName), &thisCmd))
 		Ω(thisCmd.Name()).To(Equal(commandName))
 	})
+
+	It("should find the edit command", func() {
+		var thisCmd *cobra.Command
+		Ω(rootCmd.Commands()).To(ContainElement(HaveField("Use", commandName), &thisCmd))
+		Ω(thisCmd.Commands()).To(ContainElement(HaveField("Use", "edit")))
+	})
+
+	It("should find the variations command", func() {
+		var thisCmd *cobra.Command
+		Ω(rootCmd.Commands()).To(ContainElement(HaveField("Use", commandName), &thisCmd))
+		Ω(thisCmd.Commands()).To(ContainElement(HaveField("Use", "variations")))
+	})
 })
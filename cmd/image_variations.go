@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/image/draw"
+)
+
+const (
+	// maxVariationSide is the largest square image sent, as no larger size can be generated
+	maxVariationSide          = 1024
+	maxVariationInputFileSize = 50 * 1024 * 1024
+)
+
+func newImageVariationsCmd(rootFlags *RootFlags, imageFlags *ImageFlags) *cobra.Command {
+	chatContext := NewChatContext()
+	var cmd = &cobra.Command{
+		Use:   "variations",
+		Short: "Create variations of an image",
+		Long:  "Create variations of input images with DALL-E 2, converting each to the square PNG required",
+		RunE:  imageVariationsCmdRunner(rootFlags, imageFlags, chatContext),
+	}
+	setChatContext(cmd, chatContext)
+
+	AddImageInputFlag(&imageFlags.InputFiles, cmd.Flags())
+	_ = cmd.MarkFlagRequired(FlagImageInput)
+
+	return cmd
+}
+
+func imageVariationsCmdRunner(rootFlags *RootFlags, imageFlags *ImageFlags, chatContext *ChatContext) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
+		log.Debugf("imageVariationsCmd called")
+		// variations are only supported by DALL-E 2, so it is the default here
+		if !flagChanged(cmd, FlagImageModel) {
+			imageFlags.Model = openai.CreateImageModelDallE2
+		}
+		err := imageFlags.ValidateVariationFlags()
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+
+		chatContext.InteractiveSession = detectTerminal()
+		if chatContext.InteractiveSession {
+			printImageVariationsBanner(imageFlags)
+		}
+		client, err := setupOpenAIClient(rootFlags.apikey)
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+
+		for _, inputFile := range imageFlags.InputFiles {
+			if err := sendImageVariationMessages(imageFlags, chatContext, client, inputFile); err != nil {
+				log.WithError(err).Fatal()
+			}
+		}
+		return nil
+	}
+}
+
+func printImageVariationsBanner(f *ImageFlags) {
+	TitleFmt.Printf("ChatGPT CLI v%s\n", version)
+	fmt.Printf("model: %s, numberImages: %d, size: %s\n", f.Model, f.NumberImages, f.Size)
+	fmt.Printf("input: %s\n", strings.Join(f.InputFiles, ", "))
+}
+
+// sendImageVariationMessages sends an input image, and saves the variations
+func sendImageVariationMessages(f *ImageFlags, chatContext *ChatContext, client *openai.Client, inputFile string) error {
+	data, err := squarePNGFromFile(inputFile)
+	if err != nil {
+		return err
+	}
+
+	mySpinner := newSpinner()
+	successSpinner, _ := mySpinner.Start("Sending " + inputFile + " to DALL-E, please wait...")
+	resp, err := client.CreateVariImage(context.Background(), openai.ImageVariRequest{
+		Image:          openai.WrapReader(bytes.NewReader(data), strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))+".png", "image/png"),
+		Model:          f.Model,
+		N:              f.NumberImages,
+		Size:           f.Size,
+		ResponseFormat: openai.CreateImageResponseFormatB64JSON,
+	})
+	if err != nil {
+		successSpinner.Fail(err.Error())
+		return err
+	}
+	successSpinner.Success()
+
+	metadata := newImageMetadata(f, "", resp.Created)
+	_, err = saveImageResponse(resp, f, chatContext, metadata)
+	return err
+}
+
+// squarePNGFromFile reads a PNG, JPEG, or WebP image, and converts it to a square RGBA PNG
+func squarePNGFromFile(fileName string) ([]byte, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read image: %w", err)
+	}
+	defer func(file *os.File) { _ = file.Close() }(file)
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode image %s: %w", fileName, err)
+	}
+	return squarePNG(img, maxVariationSide)
+}
+
+// squarePNG scales an image down to fit in a square of no more than maxSide pixels, centring it on a transparent
+// background, and halving it again until the encoded PNG is smaller than the 4MB allowed
+func squarePNG(img image.Image, maxSide int) ([]byte, error) {
+	bounds := img.Bounds()
+	side := max(bounds.Dx(), bounds.Dy())
+
+	target := min(side, maxSide)
+	for {
+		width := max(1, bounds.Dx()*target/side)
+		height := max(1, bounds.Dy()*target/side)
+		square := image.NewNRGBA(image.Rect(0, 0, target, target))
+		fit := image.Rect(0, 0, width, height).Add(image.Pt((target-width)/2, (target-height)/2))
+		draw.CatmullRom.Scale(square, fit, img, bounds, draw.Src, nil)
+
+		var buf bytes.Buffer
+		if err := encodeRGBAPNG(&buf, square); err != nil {
+			return nil, fmt.Errorf("PNG encode error: %w", err)
+		}
+		if buf.Len() < maxDalle2EditFileSize || target <= 1 {
+			return buf.Bytes(), nil
+		}
+		target /= 2
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Image Variations", func() {
+	decode := func(data []byte) image.Image {
+		img, err := png.Decode(bytes.NewReader(data))
+		Ω(err).ToNot(HaveOccurred())
+		return img
+	}
+
+	It("should pad a wide image to a square with transparent borders", func() {
+		img := image.NewRGBA(image.Rect(0, 0, 30, 10))
+		for x := 0; x < 30; x++ {
+			for y := 0; y < 10; y++ {
+				img.Set(x, y, color.RGBA{R: 0xff, A: 0xff})
+			}
+		}
+
+		data, err := squarePNG(img, 1024)
+		Ω(err).ToNot(HaveOccurred())
+		square := decode(data)
+		Ω(square.Bounds()).To(Equal(image.Rect(0, 0, 30, 30)))
+		Ω(square.ColorModel()).To(Equal(color.NRGBAModel))
+
+		for _, y := range []int{0, 9, 20, 29} {
+			_, _, _, a := square.At(15, y).RGBA()
+			Ω(a).To(BeZero(), "row %d", y)
+		}
+		for _, x := range []int{0, 15, 29} {
+			r, g, _, a := square.At(x, 15).RGBA()
+			Ω(r).To(Equal(uint32(0xffff)))
+			Ω(g).To(BeZero())
+			Ω(a).To(Equal(uint32(0xffff)))
+		}
+	})
+
+	It("should keep the alpha channel of an opaque image, without changing its pixels", func() {
+		img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+		for x := 0; x < 8; x++ {
+			for y := 0; y < 8; y++ {
+				img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 30), G: uint8(y * 30), B: uint8(x * y), A: 0xff})
+			}
+		}
+
+		data, err := squarePNG(img, 1024)
+		Ω(err).ToNot(HaveOccurred())
+		square := decode(data)
+		Ω(square).To(BeAssignableToTypeOf(&image.NRGBA{}))
+		Ω(square.(*image.NRGBA).Pix).To(Equal(img.Pix))
+	})
+
+	It("should scale down large images", func() {
+		data, err := squarePNG(image.NewRGBA(image.Rect(0, 0, 40, 60)), 16)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(decode(data).Bounds()).To(Equal(image.Rect(0, 0, 16, 16)))
+	})
+
+	It("should convert a JPEG file", func() {
+		fileName := filepath.Join(GinkgoT().TempDir(), "photo.jpg")
+		file, err := os.Create(fileName)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(jpeg.Encode(file, image.NewRGBA(image.Rect(0, 0, 20, 12)), nil)).To(Succeed())
+		Ω(file.Close()).To(Succeed())
+
+		data, err := squarePNGFromFile(fileName)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(decode(data).Bounds()).To(Equal(image.Rect(0, 0, 20, 20)))
+	})
+})
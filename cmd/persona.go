@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const personaMetadataKey = "persona"
+
+// Persona is a named bundle of a system message and chat parameters, loaded from the personas file
+type Persona struct {
+	Name          string        `mapstructure:"-"`
+	SystemMessage string        `mapstructure:"system_message"`
+	Model         string        `mapstructure:"model"`
+	Temperature   *float32      `mapstructure:"temperature"`
+	TopP          *float32      `mapstructure:"top_p"`
+	MaxTokens     *int          `mapstructure:"max_tokens"`
+	Tools         []openai.Tool `mapstructure:"tools"`
+}
+
+// loadPersona reads the persona with the given name from the personas file.
+// if personasFile is empty, .chatgpt-cli-personas.(yaml|json|toml) is searched for in ./ and then $HOME
+func loadPersona(personasFile string, name string) (*Persona, error) {
+	v := viper.New()
+	if personasFile != "" {
+		v.SetConfigFile(personasFile)
+	} else {
+		v.SetConfigName(".chatgpt-cli-personas")
+		v.AddConfigPath(".")
+		v.AddConfigPath("$HOME")
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("unable to read personas file: %w", err)
+	}
+	log.Debugf("personas loaded from: %s", v.ConfigFileUsed())
+
+	if !v.IsSet(name) {
+		return nil, fmt.Errorf("persona '%s' not found in %s", name, v.ConfigFileUsed())
+	}
+	persona := &Persona{}
+	if err := v.UnmarshalKey(name, persona); err != nil {
+		return nil, fmt.Errorf("unable to parse persona '%s': %w", name, err)
+	}
+	persona.Name = name
+	return persona, nil
+}
+
+// applyPersona loads the persona named in the flags, and uses it to fill in
+// any chat parameters that were not explicitly set on the command line, environment, or config file
+func applyPersona(cmd *cobra.Command, f *ChatFlags) error {
+	if f.persona == "" {
+		return nil
+	}
+	persona, err := loadPersona(f.personasFile, f.persona)
+	if err != nil {
+		return err
+	}
+
+	if persona.SystemMessage != "" && !flagChanged(cmd, FlagInitialSystemMessage) {
+		f.initialSystemMessage = persona.SystemMessage
+	}
+	if persona.Model != "" && !flagChanged(cmd, FlagModel) {
+		f.model = persona.Model
+	}
+	if persona.Temperature != nil && !flagChanged(cmd, FlagTemperature) {
+		f.temperature = *persona.Temperature
+	}
+	if persona.TopP != nil && !flagChanged(cmd, FlagTopP) {
+		f.topP = *persona.TopP
+	}
+	if persona.MaxTokens != nil && !flagChanged(cmd, FlagMaxTokens) {
+		f.maxCompletionTokens = *persona.MaxTokens
+	}
+	f.tools = persona.Tools
+	return nil
+}
+
+// flagChanged reports if the named flag exists on the command and has been set
+func flagChanged(cmd *cobra.Command, name string) bool {
+	flag := cmd.Flags().Lookup(name)
+	return flag != nil && flag.Changed
+}
+
+// recordPersona stores the active persona in the session metadata
+func recordPersona(f *ChatFlags, chat *openai.ChatCompletionRequest) {
+	if f.persona == "" {
+		return
+	}
+	if chat.Metadata == nil {
+		chat.Metadata = map[string]string{}
+	}
+	chat.Metadata[personaMetadataKey] = f.persona
+}
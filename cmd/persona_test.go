@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Persona", func() {
+	Describe("loadPersona", func() {
+		It("should load a persona by name", func() {
+			persona, err := loadPersona("test_files/personas.yaml", "translator")
+			Ω(err).ToNot(HaveOccurred())
+			Ω(persona.Name).To(Equal("translator"))
+			Ω(persona.SystemMessage).To(ContainSubstring("Japanese"))
+			Ω(persona.Model).To(Equal("gpt-4o"))
+			Ω(*persona.Temperature).To(BeNumerically("~", 0.2, 0.001))
+			Ω(*persona.MaxTokens).To(Equal(500))
+			Ω(persona.TopP).To(BeNil())
+		})
+
+		It("should load tools", func() {
+			persona, err := loadPersona("test_files/personas.yaml", "reviewer")
+			Ω(err).ToNot(HaveOccurred())
+			Ω(persona.Tools).To(HaveLen(1))
+			Ω(persona.Tools[0].Type).To(Equal(openai.ToolTypeFunction))
+			Ω(persona.Tools[0].Function.Name).To(Equal("lookup_file"))
+		})
+
+		It("should fail on an unknown persona", func() {
+			_, err := loadPersona("test_files/personas.yaml", "missing")
+			Ω(err).To(HaveOccurred())
+			Ω(err.Error()).To(ContainSubstring("persona 'missing' not found"))
+		})
+
+		It("should fail on a missing personas file", func() {
+			_, err := loadPersona("test_files/missing.yaml", "translator")
+			Ω(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("applyPersona", func() {
+		var cmd *cobra.Command
+		var f *ChatFlags
+
+		BeforeEach(func() {
+			cmd = &cobra.Command{}
+			f = NewChatFlags()
+			AddModelFlag(&f.model, cmd.Flags())
+			AddTemperatureFlag(&f.temperature, cmd.Flags())
+			AddInitialSystemMessageFlag(&f.initialSystemMessage, cmd.Flags())
+			f.persona = "translator"
+			f.personasFile = "test_files/personas.yaml"
+		})
+
+		It("should fill in flags that were not set", func() {
+			Ω(applyPersona(cmd, f)).To(Succeed())
+			Ω(f.model).To(Equal("gpt-4o"))
+			Ω(f.temperature).To(BeNumerically("~", 0.2, 0.001))
+			Ω(f.maxCompletionTokens).To(Equal(500))
+			Ω(f.initialSystemMessage).To(ContainSubstring("Japanese"))
+		})
+
+		It("should not override flags that were set", func() {
+			Ω(cmd.Flags().Set(FlagModel, "gpt-5")).To(Succeed())
+			Ω(applyPersona(cmd, f)).To(Succeed())
+			Ω(f.model).To(Equal("gpt-5"))
+		})
+
+		It("should record the persona in the session metadata", func() {
+			chat := &openai.ChatCompletionRequest{}
+			recordPersona(f, chat)
+			Ω(chat.Metadata).To(HaveKeyWithValue("persona", "translator"))
+			Ω(apiChatCompletionRequest(chat).Metadata).To(BeNil())
+		})
+	})
+})
@@ -41,7 +41,7 @@ func replaySessionCmdRun(f *ReplaySessionFlags) func(cmd *cobra.Command, args []
 			} else {
 				AiFmt.Printf("%s:\n", message.Role)
 			}
-			fmt.Printf("%s\n", message.Content)
+			fmt.Printf("%s\n", messageText(message))
 		}
 		fmt.Println()
 	}
@@ -35,6 +35,9 @@ func NewRootCmd() *cobra.Command {
 	cmds.AddCommand(NewReplaySessionCmd())
 	cmds.AddCommand(NewVersionCmd())
 	cmds.AddCommand(NewTranscriptionCmd(rootFlags))
+	cmds.AddCommand(NewTranslateAudioCmd(rootFlags))
+	cmds.AddCommand(NewAskShellCmd(rootFlags))
+	cmds.AddCommand(NewGitCmd(rootFlags))
 
 	AddConfigFileFlag(&rootFlags.configFile, cmds.PersistentFlags())
 	AddApiKeyFlag(&rootFlags.apikey, cmds.PersistentFlags())
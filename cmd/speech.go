@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
 	"time"
+	"unicode/utf8"
 
 	os2 "github.com/duanemay/chatgpt-cli/pkg/os"
 	"github.com/sashabaranov/go-openai"
@@ -14,6 +16,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// speechOutputStdout is the --output that writes the audio to stdout
+const speechOutputStdout = "-"
+
 func NewSpeechCmd(rootFlags *RootFlags) *cobra.Command {
 	speechFlags := NewSpeechFlags()
 	chatContext := NewChatContext()
@@ -29,9 +34,16 @@ func NewSpeechCmd(rootFlags *RootFlags) *cobra.Command {
 	AddSpeechModelFlag(&speechFlags.ModelStr, cmd.PersistentFlags())
 	AddSpeedFlag(&speechFlags.Speed, cmd.PersistentFlags())
 	AddVoiceFlag(&speechFlags.VoiceStr, cmd.PersistentFlags())
+	AddInstructionsFlag(&speechFlags.Instructions, cmd.PersistentFlags())
+	AddSpeechFormatFlag(&speechFlags.FormatStr, cmd.PersistentFlags())
+	AddOutputFlag(&speechFlags.Output, cmd.PersistentFlags())
+	AddConcurrencyFlag(&speechFlags.Concurrency, cmd.PersistentFlags())
+	AddResumeFlag(&speechFlags.Resume, cmd.PersistentFlags())
 	AddOutputPrefixFlag(&speechFlags.OutputPrefix, "tts-"+time.Now().UTC().Format(time.RFC3339), cmd.PersistentFlags())
 	_ = cmd.MarkPersistentFlagRequired("apikey")
 
+	cmd.AddCommand(newSpeechVoicesCmd(rootFlags, speechFlags))
+
 	return cmd
 }
 
@@ -44,6 +56,10 @@ func speechCmdRunner(rootFlags *RootFlags, speechFlags *SpeechFlags, chatContext
 		}
 
 		chatContext.InteractiveSession = detectTerminal()
+		if err := speechFlags.ValidateInteractiveFlags(chatContext.InteractiveSession); err != nil {
+			log.WithError(err).Fatal()
+		}
+		speechFlags.Play = chatContext.InteractiveSession
 		if chatContext.InteractiveSession {
 			printSpeechBanner(speechFlags)
 		}
@@ -60,7 +76,7 @@ func speechCmdRunner(rootFlags *RootFlags, speechFlags *SpeechFlags, chatContext
 				return nil
 			}
 
-			if err := sendSpeechMessages(speechFlags, chatContext, client, chatRequestString); err != nil {
+			if err := sendSpeechMessages(speechFlags, client, chatRequestString); err != nil {
 				log.WithError(err).Fatal()
 			}
 
@@ -74,55 +90,108 @@ func speechCmdRunner(rootFlags *RootFlags, speechFlags *SpeechFlags, chatContext
 
 func printSpeechBanner(f *SpeechFlags) {
 	TitleFmt.Printf("ChatGPT CLI v%s\n", version)
-	fmt.Printf("Model: %s, Voice: %s, speed: %0.2f\n", f.Model, f.Voice, f.Speed)
+	fmt.Printf("Model: %s, Voice: %s, speed: %0.2f, format: %s\n", f.Model, f.Voice, f.Speed, f.Format)
+	if f.Instructions != "" {
+		fmt.Printf("Instructions: %s\n", f.Instructions)
+	}
 	fmt.Printf("- Press TAB after entering a message to send.\n")
 	fmt.Printf("- Press TAB or CTRL+C with a blank message to terminate the session without sending.\n")
 }
 
-// sendMessages sends messages to ChatGPT and prints the response
-func sendSpeechMessages(f *SpeechFlags, chatContext *ChatContext, client *openai.Client, chatRequestString string) error {
-	mySpinner := newSpinner()
-	successSpinner, _ := mySpinner.Start("Sending to ChatGPT TTS please wait...")
+// sendSpeechMessages reads the text aloud, and saves the audio, or writes it to stdout.
+// Text longer than the API reads at once is split into chunks, and their audio joined.
+func sendSpeechMessages(f *SpeechFlags, client *openai.Client, chatRequestString string) error {
+	chunks := splitSpeechText(chatRequestString, maxSpeechInputLength)
+	if len(chunks) == 1 {
+		mySpinner := newSpinner()
+		successSpinner, _ := mySpinner.Start("Sending to ChatGPT TTS please wait...")
+		resp, err := client.CreateSpeech(context.Background(), newSpeechRequest(f, chunks[0]))
+		if err != nil {
+			successSpinner.Fail(err.Error())
+			return err
+		}
+		successSpinner.Success()
+		defer func(resp openai.RawResponse) { _ = resp.Close() }(resp)
+		return writeSpeechAudio(f, resp)
+	}
 
-	imageRequest := openai.CreateSpeechRequest{
+	if !canJoinSpeechFormat(f.Format) {
+		return fmt.Errorf("text longer than %d characters can only be joined in mp3, wav, or pcm format", maxSpeechInputLength)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Text is %d characters, sending in %d chunks\n", utf8.RuneCountInString(chatRequestString), len(chunks))
+	dir := speechChunksDir(f, chunks)
+	parts, err := synthesizeSpeechChunks(f, client, chunks, dir)
+	if err != nil {
+		return err
+	}
+	audio, err := joinSpeechAudio(f.Format, parts)
+	if err != nil {
+		return err
+	}
+	if err := writeSpeechAudio(f, bytes.NewReader(audio)); err != nil {
+		return err
+	}
+	_ = os.RemoveAll(dir)
+	return nil
+}
+
+func newSpeechRequest(f *SpeechFlags, text string) openai.CreateSpeechRequest {
+	return openai.CreateSpeechRequest{
 		Model:          f.Model,
-		Input:          chatRequestString,
-		ResponseFormat: openai.SpeechResponseFormatMp3,
+		Input:          text,
+		ResponseFormat: f.Format,
 		Voice:          f.Voice,
+		Instructions:   f.Instructions,
 		Speed:          f.Speed,
 	}
-	resp, err := client.CreateSpeech(context.Background(), imageRequest)
-	if err != nil {
-		successSpinner.Fail(err.Error())
-		return err
+}
+
+// writeSpeechAudio streams the audio to stdout, or saves it to a file, printing the file name,
+// and opening it to play
+func writeSpeechAudio(f *SpeechFlags, audio io.Reader) error {
+	if f.Output == speechOutputStdout {
+		// stream the audio as it arrives, for piping into a player
+		if _, err := io.Copy(os.Stdout, audio); err != nil {
+			return fmt.Errorf("audio write error: %w", err)
+		}
+		return nil
 	}
-	successSpinner.Success()
 
+	notices := os.Stdout
+	if f.NoticesToStderr {
+		notices = os.Stderr
+	}
 	fileName := getSpeechFileName(f)
 	file, err := os.Create(fileName)
 	if err != nil {
-		fmt.Printf("File creation error: %v\n", err)
+		_, _ = fmt.Fprintf(notices, "File creation error: %v\n", err)
 		return err
 	}
 	defer func(file *os.File) { _ = file.Close() }(file)
 
-	_, err = io.Copy(file, resp)
+	_, err = io.Copy(file, audio)
 	if err != nil {
-		fmt.Printf("File copy error: %v\n", err)
+		_, _ = fmt.Fprintf(notices, "File copy error: %v\n", err)
 		return err
 	}
 
-	fmt.Printf("%s\n", fileName)
-	if chatContext.InteractiveSession {
-		os2.OpenBrowser(fileName)
+	_, _ = fmt.Fprintf(notices, "%s\n", fileName)
+	if f.Play {
+		if err := os2.OpenBrowser(fileName); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s\n", ErrorFmt.Sprintf("%v", err))
+		}
 	}
 
 	return nil
 }
 
+// getSpeechFileName returns the --output file, or the next numbered file name, with the extension of the format
 func getSpeechFileName(f *SpeechFlags) string {
+	if f.Output != "" {
+		return f.Output
+	}
 	thisImageCount := f.CurrentImageCount
 	f.CurrentImageCount = thisImageCount + 1
-	filename := fmt.Sprintf("%s-%02d.mp3", f.OutputPrefix, thisImageCount)
+	filename := fmt.Sprintf("%s-%02d.%s", f.OutputPrefix, thisImageCount, f.Format)
 	return filename
 }
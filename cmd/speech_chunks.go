@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// maxSpeechInputLength is the most characters the speech API reads in one request
+const maxSpeechInputLength = 4096
+
+// speechBoundaries are the places long text is split, in order of preference: paragraphs, sentences, then words
+var speechBoundaries = []*regexp.Regexp{
+	regexp.MustCompile(`\n\s*\n`),
+	regexp.MustCompile(`[.!?]["')\]]*\s+`),
+	regexp.MustCompile(`\s+`),
+}
+
+// splitSpeechText splits text into chunks of at most maxLength characters, at the largest boundary that fits
+func splitSpeechText(text string, maxLength int) []string {
+	return packSpeechText(strings.TrimSpace(text), maxLength, 0)
+}
+
+func packSpeechText(text string, maxLength int, level int) []string {
+	if utf8.RuneCountInString(text) <= maxLength {
+		return []string{text}
+	}
+	if level == len(speechBoundaries) {
+		// a single word longer than the limit, split it anywhere
+		runes := []rune(text)
+		var chunks []string
+		for i := 0; i < len(runes); i += maxLength {
+			chunks = append(chunks, string(runes[i:min(i+maxLength, len(runes))]))
+		}
+		return chunks
+	}
+
+	var chunks []string
+	current := ""
+	for _, piece := range splitAfter(text, speechBoundaries[level]) {
+		if utf8.RuneCountInString(strings.TrimSpace(current+piece)) <= maxLength {
+			current += piece
+			continue
+		}
+		if strings.TrimSpace(current) != "" {
+			chunks = append(chunks, strings.TrimSpace(current))
+		}
+		current = piece
+		if utf8.RuneCountInString(strings.TrimSpace(piece)) > maxLength {
+			chunks = append(chunks, packSpeechText(strings.TrimSpace(piece), maxLength, level+1)...)
+			current = ""
+		}
+	}
+	if strings.TrimSpace(current) != "" {
+		chunks = append(chunks, strings.TrimSpace(current))
+	}
+	return chunks
+}
+
+// splitAfter splits text after each match of the boundary, keeping the boundary with the text before it
+func splitAfter(text string, boundary *regexp.Regexp) []string {
+	var pieces []string
+	start := 0
+	for _, match := range boundary.FindAllStringIndex(text, -1) {
+		pieces = append(pieces, text[start:match[1]])
+		start = match[1]
+	}
+	if start < len(text) {
+		pieces = append(pieces, text[start:])
+	}
+	return pieces
+}
+
+// canJoinSpeechFormat reports if audio in the format can be joined, without decoding it
+func canJoinSpeechFormat(format openai.SpeechResponseFormat) bool {
+	switch format {
+	case openai.SpeechResponseFormatMp3, openai.SpeechResponseFormatWav, openai.SpeechResponseFormatPcm:
+		return true
+	default:
+		return false
+	}
+}
+
+// speechChunksDir is where the audio for each chunk is kept until it is joined, named from the text and the
+// flags, so a run with the same input can --resume
+func speechChunksDir(f *SpeechFlags, chunks []string) string {
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%s\x00%s\x00%g\x00%s\x00%s\x00", f.Model, f.Voice, f.Speed, f.Format, f.Instructions)
+	for _, chunk := range chunks {
+		_, _ = fmt.Fprintf(hash, "%s\x00", chunk)
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("chatgpt-cli-tts-%x", hash.Sum(nil)[:8]))
+}
+
+// synthesizeSpeechChunks creates the audio for each chunk, no more than --concurrency at a time, saving each in dir.
+// With --resume, chunks already in dir are reused.
+func synthesizeSpeechChunks(f *SpeechFlags, client *openai.Client, chunks []string, dir string) ([][]byte, error) {
+	if !f.Resume {
+		_ = os.RemoveAll(dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create directory: %w", err)
+	}
+
+	audio := make([][]byte, len(chunks))
+	errs := make([]error, len(chunks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	work := make(chan int)
+	for range min(f.Concurrency, len(chunks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				fileName := filepath.Join(dir, fmt.Sprintf("chunk-%03d.%s", i+1, f.Format))
+				status := "reused"
+				data, err := os.ReadFile(fileName)
+				if err != nil || len(data) == 0 {
+					status = "done"
+					data, err = createSpeech(f, client, chunks[i])
+					if err == nil {
+						err = writeFileAtomically(fileName, data)
+					}
+				}
+				audio[i], errs[i] = data, err
+
+				mu.Lock()
+				done++
+				if err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "%s\n", ErrorFmt.Sprintf("[%d/%d] chunk %d failed: %v", done, len(chunks), i+1, err))
+				} else {
+					_, _ = fmt.Fprintf(os.Stderr, "[%d/%d] chunk %d %s\n", done, len(chunks), i+1, status)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range chunks {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("%w\nthe chunks created are kept in %s, run again with --%s to reuse them", err, dir, FlagResume)
+	}
+	return audio, nil
+}
+
+// writeFileAtomically writes to a temporary file, then renames it, so an interrupted run never leaves a partial
+// chunk to be reused by --resume
+func writeFileAtomically(fileName string, data []byte) error {
+	tempFile := fileName + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempFile, fileName)
+}
+
+// createSpeech reads the text aloud, returning the whole audio
+func createSpeech(f *SpeechFlags, client *openai.Client, text string) ([]byte, error) {
+	resp, err := client.CreateSpeech(context.Background(), newSpeechRequest(f, text))
+	if err != nil {
+		return nil, err
+	}
+	defer func(resp openai.RawResponse) { _ = resp.Close() }(resp)
+	return io.ReadAll(resp)
+}
+
+// joinSpeechAudio joins the audio for each chunk into one: PCM samples are concatenated, WAV data is
+// concatenated under one header, and MP3 frames are concatenated without the tags between them
+func joinSpeechAudio(format openai.SpeechResponseFormat, parts [][]byte) ([]byte, error) {
+	switch format {
+	case openai.SpeechResponseFormatPcm:
+		return bytes.Join(parts, nil), nil
+	case openai.SpeechResponseFormatWav:
+		return joinWAV(parts)
+	case openai.SpeechResponseFormatMp3:
+		return joinMP3(parts)
+	default:
+		return nil, fmt.Errorf("text longer than %d characters can only be joined in mp3, wav, or pcm format", maxSpeechInputLength)
+	}
+}
+
+// joinWAV concatenates the samples of WAV files with the same format
+func joinWAV(parts [][]byte) ([]byte, error) {
+	var format, data []byte
+	for i, part := range parts {
+		partFormat, partData, err := readWAV(part)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", i+1, err)
+		}
+		if format == nil {
+			format = partFormat
+		} else if !bytes.Equal(format, partFormat) {
+			return nil, fmt.Errorf("chunk %d: WAV format differs from the first chunk", i+1)
+		}
+		data = append(data, partData...)
+	}
+
+	return append(wavHeaderBytes(format, len(data)), data...), nil
+}
+
+// wavHeaderBytes returns the RIFF, fmt, and data headers of a WAV file with dataSize bytes of audio
+func wavHeaderBytes(format []byte, dataSize int) []byte {
+	header := make([]byte, 0, wavHeaderSize(format))
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(4+8+len(format)+8+dataSize))
+	header = append(header, "WAVE"...)
+	header = append(header, "fmt "...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(format)))
+	header = append(header, format...)
+	header = append(header, "data"...)
+	return binary.LittleEndian.AppendUint32(header, uint32(dataSize))
+}
+
+// readWAV returns the fmt and data chunks of a WAV file. Streamed WAV files may not know the size of their data,
+// so the data runs to the end of the file when its size is too large.
+func readWAV(wav []byte) (format []byte, data []byte, err error) {
+	if len(wav) < 12 || string(wav[0:4]) != "RIFF" || string(wav[8:12]) != "WAVE" {
+		return nil, nil, fmt.Errorf("not a WAV file")
+	}
+	for offset := 12; offset+8 <= len(wav); {
+		id := string(wav[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(wav[offset+4 : offset+8]))
+		start := offset + 8
+		end := start + size
+		if end > len(wav) || end < start {
+			end = len(wav)
+		}
+		switch id {
+		case "fmt ":
+			format = wav[start:end]
+		case "data":
+			if format == nil {
+				return nil, nil, fmt.Errorf("WAV data before format")
+			}
+			return format, wav[start:end], nil
+		}
+		offset = end + size%2
+	}
+	return nil, nil, fmt.Errorf("WAV file has no data")
+}
+
+// joinMP3 concatenates the frames of MP3 files, keeping only the ID3v2 tag at the start of the first,
+// and the ID3v1 tag at the end of the last. The Xing, Info, or VBRI frame of each file is dropped, as it gives the
+// length of that file alone, which players would take as the length of the joined audio.
+func joinMP3(parts [][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, part := range parts {
+		tag := id3v2Size(part)
+		if i == 0 {
+			buf.Write(part[:tag])
+		}
+		part = part[tag:]
+		start := mp3FrameStart(part)
+		if start < 0 {
+			return nil, fmt.Errorf("chunk %d: no MP3 frames found", i+1)
+		}
+		part = part[start:]
+		if length := mp3FrameLength(part); length > 0 && length <= len(part) && isMP3InfoFrame(part[:length]) {
+			part = part[length:]
+		}
+		if i < len(parts)-1 && len(part) >= 128 && string(part[len(part)-128:len(part)-125]) == "TAG" {
+			part = part[:len(part)-128]
+		}
+		buf.Write(part)
+	}
+	return buf.Bytes(), nil
+}
+
+// id3v2Size returns the size of the ID3v2 tag at the start of an MP3 file, or 0 if there is none
+func id3v2Size(mp3 []byte) int {
+	if len(mp3) < 10 || string(mp3[0:3]) != "ID3" {
+		return 0
+	}
+	// the size is syncsafe, 7 bits in each byte, and does not include the header, or footer if there is one
+	size := 10 + (int(mp3[6])<<21 | int(mp3[7])<<14 | int(mp3[8])<<7 | int(mp3[9]))
+	if mp3[5]&0x10 != 0 {
+		size += 10
+	}
+	return min(size, len(mp3))
+}
+
+// mp3FrameStart returns the offset of the first frame sync, 11 set bits, or -1 if there is none
+func mp3FrameStart(mp3 []byte) int {
+	for i := 0; i+1 < len(mp3); i++ {
+		if mp3[i] == 0xff && mp3[i+1]&0xe0 == 0xe0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// MPEG audio version and layer of a frame header, as they are stored in its second byte
+const (
+	mpegVersion1  = 3
+	mpegVersion2  = 2
+	mpegVersion25 = 0
+	mpegLayer3    = 1
+)
+
+var (
+	// mp3Bitrates are the bitrates of Layer III, in kbit/s, by bitrate index, for MPEG-1, then MPEG-2 and 2.5
+	mp3Bitrates = [2][15]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	// mp3SampleRates are the sample rates, by sample rate index, for each MPEG version
+	mp3SampleRates = map[int][3]int{
+		mpegVersion1:  {44100, 48000, 32000},
+		mpegVersion2:  {22050, 24000, 16000},
+		mpegVersion25: {11025, 12000, 8000},
+	}
+)
+
+// mp3FrameLength returns the length of the Layer III frame at the start of mp3, or 0 if it is not a frame header
+// that can be read
+func mp3FrameLength(mp3 []byte) int {
+	if len(mp3) < 4 || mp3[0] != 0xff || mp3[1]&0xe0 != 0xe0 {
+		return 0
+	}
+	version, layer := int(mp3[1]>>3&3), int(mp3[1]>>1&3)
+	bitrateIndex, sampleRateIndex, padding := int(mp3[2]>>4), int(mp3[2]>>2&3), int(mp3[2]>>1&1)
+	sampleRates, ok := mp3SampleRates[version]
+	if !ok || layer != mpegLayer3 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return 0
+	}
+
+	if version == mpegVersion1 {
+		return 144*mp3Bitrates[0][bitrateIndex]*1000/sampleRates[sampleRateIndex] + padding
+	}
+	return 72*mp3Bitrates[1][bitrateIndex]*1000/sampleRates[sampleRateIndex] + padding
+}
+
+// isMP3InfoFrame reports if a frame holds a Xing or Info header, after the side information, or a VBRI header,
+// rather than audio
+func isMP3InfoFrame(frame []byte) bool {
+	version, mono := int(frame[1]>>3&3), frame[3]>>6 == 3
+	sideInfo := 17
+	switch {
+	case version == mpegVersion1 && !mono:
+		sideInfo = 32
+	case version != mpegVersion1 && mono:
+		sideInfo = 9
+	}
+	hasHeader := func(offset int, ids ...string) bool {
+		for _, id := range ids {
+			if len(frame) >= offset+4 && string(frame[offset:offset+4]) == id {
+				return true
+			}
+		}
+		return false
+	}
+	return hasHeader(4+sideInfo, "Xing", "Info") || hasHeader(4+32, "VBRI")
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func wav(format []byte, data []byte, dataSize uint32) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0xffffffff))
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(format)))
+	buf.Write(format)
+	buf.WriteString("LIST")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(3))
+	buf.WriteString("abc\x00")
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, dataSize)
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+var _ = Describe("Speech Chunks", func() {
+	It("should not split short text", func() {
+		Ω(splitSpeechText("  Hello there.  ", 100)).To(Equal([]string{"Hello there."}))
+	})
+
+	It("should split on paragraphs, then sentences, then words", func() {
+		text := "First paragraph. It is short.\n\nSecond paragraph is here."
+		Ω(splitSpeechText(text, 40)).To(Equal([]string{"First paragraph. It is short.", "Second paragraph is here."}))
+
+		text = "One sentence here. Another sentence there! A third one?"
+		Ω(splitSpeechText(text, 25)).To(Equal([]string{"One sentence here.", "Another sentence there!", "A third one?"}))
+
+		Ω(splitSpeechText("a very long sentence without stops", 12)).To(Equal([]string{"a very long", "sentence", "without", "stops"}))
+		Ω(splitSpeechText("abcdefghij", 4)).To(Equal([]string{"abcd", "efgh", "ij"}))
+	})
+
+	It("should keep every chunk within the limit, and all the words", func() {
+		text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 300)
+		chunks := splitSpeechText(text, maxSpeechInputLength)
+		Ω(len(chunks)).To(BeNumerically(">", 1))
+		for _, chunk := range chunks {
+			Ω(len(chunk)).To(BeNumerically("<=", maxSpeechInputLength))
+		}
+		Ω(strings.Fields(strings.Join(chunks, " "))).To(Equal(strings.Fields(text)))
+	})
+
+	It("should join WAV data under one header", func() {
+		format := []byte("0123456789abcdef")
+		joined, err := joinSpeechAudio(openai.SpeechResponseFormatWav, [][]byte{
+			wav(format, []byte("first"), 5),
+			wav(format, []byte("second"), 0xffffffff),
+		})
+		Ω(err).ToNot(HaveOccurred())
+		joinedFormat, data, err := readWAV(joined)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(joinedFormat).To(Equal(format))
+		Ω(string(data)).To(Equal("firstsecond"))
+		Ω(binary.LittleEndian.Uint32(joined[4:8])).To(BeEquivalentTo(len(joined) - 8))
+
+		_, err = joinSpeechAudio(openai.SpeechResponseFormatWav, [][]byte{wav(format, nil, 0), wav([]byte("other format...."), nil, 0)})
+		Ω(err).To(MatchError(ContainSubstring("format differs")))
+	})
+
+	It("should join MP3 frames without the tags between them", func() {
+		id3 := []byte("ID3\x04\x00\x00\x00\x00\x00\x02xx")
+		frames := []byte{0xff, 0xfb, 0x90, 0x64}
+		tag := append([]byte("TAG"), make([]byte, 125)...)
+		first := append(append(append([]byte{}, id3...), frames...), tag...)
+		second := append(append(append([]byte{}, id3...), frames...), tag...)
+
+		joined, err := joinSpeechAudio(openai.SpeechResponseFormatMp3, [][]byte{first, second})
+		Ω(err).ToNot(HaveOccurred())
+		expected := append(append(append(append([]byte{}, id3...), frames...), frames...), tag...)
+		Ω(joined).To(Equal(expected))
+	})
+
+	It("should drop the Xing and Info frames, which give the length of each chunk alone", func() {
+		// MPEG-1 Layer III frames at 128 kbit/s and 44.1 kHz, in stereo, are 417 bytes
+		frame := func(fill byte, id string) []byte {
+			data := bytes.Repeat([]byte{fill}, 417)
+			copy(data, []byte{0xff, 0xfb, 0x90, 0x04})
+			copy(data[4+32:], id)
+			return data
+		}
+		Ω(mp3FrameLength(frame(1, ""))).To(Equal(417))
+		Ω(isMP3InfoFrame(frame(1, "Xing"))).To(BeTrue())
+		Ω(isMP3InfoFrame(frame(1, ""))).To(BeFalse())
+
+		first := append(frame(0, "Info"), frame(1, "")...)
+		second := append(frame(0, "Xing"), frame(2, "")...)
+		joined, err := joinSpeechAudio(openai.SpeechResponseFormatMp3, [][]byte{first, second})
+		Ω(err).ToNot(HaveOccurred())
+		Ω(joined).To(Equal(append(frame(1, ""), frame(2, "")...)))
+	})
+
+	It("should only join formats that can be concatenated", func() {
+		Ω(joinSpeechAudio(openai.SpeechResponseFormatPcm, [][]byte{[]byte("ab"), []byte("cd")})).To(Equal([]byte("abcd")))
+		_, err := joinSpeechAudio(openai.SpeechResponseFormatFlac, [][]byte{[]byte("ab"), []byte("cd")})
+		Ω(err).To(MatchError(ContainSubstring("can only be joined")))
+		Ω(canJoinSpeechFormat(openai.SpeechResponseFormatOpus)).To(BeFalse())
+	})
+
+	It("should reuse the chunks already created with --resume", func() {
+		f := NewSpeechFlags()
+		f.Format = openai.SpeechResponseFormatPcm
+		f.Resume = true
+		chunks := []string{"one", "two"}
+		dir := filepath.Join(GinkgoT().TempDir(), "chunks")
+		Ω(os.MkdirAll(dir, 0755)).To(Succeed())
+		Ω(os.WriteFile(filepath.Join(dir, "chunk-001.pcm"), []byte("1"), 0644)).To(Succeed())
+		Ω(os.WriteFile(filepath.Join(dir, "chunk-002.pcm"), []byte("2"), 0644)).To(Succeed())
+
+		// no client is needed, as every chunk is reused
+		audio, err := synthesizeSpeechChunks(f, nil, chunks, dir)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(audio).To(Equal([][]byte{[]byte("1"), []byte("2")}))
+	})
+
+	It("should only write a chunk under its own name once it is complete", func() {
+		fileName := filepath.Join(GinkgoT().TempDir(), "chunk-001.mp3")
+		Ω(writeFileAtomically(fileName, []byte("audio"))).To(Succeed())
+		Ω(os.ReadFile(fileName)).To(Equal([]byte("audio")))
+		Ω(fileName + ".tmp").ToNot(BeAnExistingFile())
+	})
+
+	It("should keep chunks for the same text and flags in the same place", func() {
+		f := NewSpeechFlags()
+		f.Voice = openai.VoiceAlloy
+		Ω(speechChunksDir(f, []string{"a", "b"})).To(Equal(speechChunksDir(f, []string{"a", "b"})))
+		Ω(speechChunksDir(f, []string{"a", "b"})).ToNot(Equal(speechChunksDir(f, []string{"a", "c"})))
+		f.Voice = openai.VoiceEcho
+		Ω(speechChunksDir(f, []string{"a", "b"})).ToNot(Equal(speechChunksDir(NewSpeechFlags(), []string{"a", "b"})))
+	})
+})
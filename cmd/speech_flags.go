@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/sashabaranov/go-openai"
 )
@@ -13,13 +15,28 @@ type SpeechFlags struct {
 	VoiceStr string
 	Voice    openai.SpeechVoice
 
+	FormatStr string
+	Format    openai.SpeechResponseFormat
+
+	Instructions string
+
 	Speed             float64
+	Output            string
 	OutputPrefix      string
+	Play              bool
 	CurrentImageCount int
+	// NoticesToStderr prints the file names to stderr, keeping them apart from chat responses on stdout
+	NoticesToStderr bool
+
+	// Concurrency and Resume are used for text long enough to be split into chunks
+	Concurrency int
+	Resume      bool
 }
 
 func NewSpeechFlags() *SpeechFlags {
 	return &SpeechFlags{
+		FormatStr:         defaultSpeechFormat,
+		Concurrency:       defaultConcurrency,
 		CurrentImageCount: 1,
 	}
 }
@@ -29,27 +46,105 @@ func (f *SpeechFlags) ValidateFlags() error {
 		return fmt.Errorf("speed must be between 0.25 and 4.0, inclusive")
 	}
 
+	if f.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+
 	switch f.ModelStr {
-	case string(openai.TTSModel1), string(openai.TTSModel1HD), string(openai.TTSModelCanary):
+	case string(openai.TTSModel1), string(openai.TTSModel1HD), string(openai.TTSModelCanary), string(openai.TTSModelGPT4oMini):
 		// these are fine
 		f.Model = openai.SpeechModel(f.ModelStr)
 
 	default:
-		return fmt.Errorf("model must be one of tts-1, tts-1-hd, or canary-tts")
+		return fmt.Errorf("model must be one of tts-1, tts-1-hd, canary-tts, or gpt-4o-mini-tts")
 	}
 
-	switch f.VoiceStr {
-	case string(openai.VoiceAlloy),
-		string(openai.VoiceEcho),
-		string(openai.VoiceFable),
-		string(openai.VoiceOnyx),
-		string(openai.VoiceNova),
-		string(openai.VoiceShimmer):
-		// these are fine
-		f.Voice = openai.SpeechVoice(f.VoiceStr)
+	voices := speechVoicesFor(f.Model)
+	if !slices.Contains(voices, openai.SpeechVoice(f.VoiceStr)) {
+		return fmt.Errorf("voice must be one of %s, for %s", joinSpeechVoices(voices), f.Model)
+	}
+	f.Voice = openai.SpeechVoice(f.VoiceStr)
+
+	if f.Instructions != "" && !supportsSpeechInstructions(f.Model) {
+		return fmt.Errorf("instructions are only supported by %s", openai.TTSModelGPT4oMini)
+	}
+
+	switch f.FormatStr {
+	case string(openai.SpeechResponseFormatMp3),
+		string(openai.SpeechResponseFormatOpus),
+		string(openai.SpeechResponseFormatAac),
+		string(openai.SpeechResponseFormatFlac),
+		string(openai.SpeechResponseFormatWav),
+		string(openai.SpeechResponseFormatPcm):
+		// these are fine, and each is also the file extension
+		f.Format = openai.SpeechResponseFormat(f.FormatStr)
 
 	default:
-		return fmt.Errorf("voice must be one of alloy, echo, fable, onyx, nova, or shimmer")
+		return fmt.Errorf("format must be one of mp3, opus, aac, flac, wav, or pcm")
+	}
+	return nil
+}
+
+// ValidateInteractiveFlags checks --output can be used, which names the one file written when the text is piped in.
+// In an interactive session, each message would overwrite the file, and with - the prompts would be mixed into the audio.
+func (f *SpeechFlags) ValidateInteractiveFlags(interactive bool) error {
+	if !interactive {
+		return nil
+	}
+	if f.Output == speechOutputStdout {
+		return fmt.Errorf("output - writes the audio to stdout, so the text must be piped in, not typed")
+	}
+	if f.Output != "" {
+		return fmt.Errorf("output names a single file, so the text must be piped in, use output-prefix to name the files of an interactive session")
 	}
 	return nil
 }
+
+// voices added after the go-openai constants
+const (
+	VoiceSage  openai.SpeechVoice = "sage"
+	VoiceMarin openai.SpeechVoice = "marin"
+	VoiceCedar openai.SpeechVoice = "cedar"
+)
+
+// speechVoices are spoken by every model
+var speechVoices = []openai.SpeechVoice{
+	openai.VoiceAlloy,
+	openai.VoiceAsh,
+	openai.VoiceCoral,
+	openai.VoiceEcho,
+	openai.VoiceFable,
+	openai.VoiceNova,
+	openai.VoiceOnyx,
+	VoiceSage,
+	openai.VoiceShimmer,
+}
+
+// steerableSpeechVoices are only spoken by the models that follow instructions
+var steerableSpeechVoices = []openai.SpeechVoice{
+	openai.VoiceBallad,
+	openai.VoiceVerse,
+	VoiceMarin,
+	VoiceCedar,
+}
+
+// speechVoicesFor returns the voices the model speaks
+func speechVoicesFor(model openai.SpeechModel) []openai.SpeechVoice {
+	if supportsSpeechInstructions(model) {
+		return slices.Concat(speechVoices, steerableSpeechVoices)
+	}
+	return speechVoices
+}
+
+// supportsSpeechInstructions reports if the model can be steered with instructions for tone, accent, and pacing
+func supportsSpeechInstructions(model openai.SpeechModel) bool {
+	return model == openai.TTSModelGPT4oMini
+}
+
+func joinSpeechVoices(voices []openai.SpeechVoice) string {
+	names := make([]string, len(voices))
+	for i, voice := range voices {
+		names[i] = string(voice)
+	}
+	return strings.Join(names, ", ")
+}
@@ -32,6 +32,19 @@ var _ = Describe("Speech Flags", func() {
 		Ω(err).Error().ToNot(HaveOccurred())
 	})
 
+	It("should only allow an output file when the text is piped in", func() {
+		speechFlags := cmd.NewSpeechFlags()
+		Ω(speechFlags.ValidateInteractiveFlags(true)).To(Succeed())
+
+		speechFlags.Output = "-"
+		Ω(speechFlags.ValidateInteractiveFlags(false)).To(Succeed())
+		Ω(speechFlags.ValidateInteractiveFlags(true)).To(MatchError(ContainSubstring("output - writes the audio to stdout")))
+
+		speechFlags.Output = "reply.mp3"
+		Ω(speechFlags.ValidateInteractiveFlags(false)).To(Succeed())
+		Ω(speechFlags.ValidateInteractiveFlags(true)).To(MatchError(ContainSubstring("output names a single file")))
+	})
+
 	It("should validate Model", func() {
 		speechFlags := cmd.NewSpeechFlags()
 		speechFlags.Speed = 1.0
@@ -101,4 +114,66 @@ var _ = Describe("Speech Flags", func() {
 		Ω(err).Error().ToNot(HaveOccurred())
 		Ω(speechFlags.Voice).To(Equal(openai.VoiceShimmer))
 	})
+
+	It("should validate Format", func() {
+		speechFlags := cmd.NewSpeechFlags()
+		speechFlags.Speed = 1.0
+		speechFlags.ModelStr = string(openai.TTSModel1)
+		speechFlags.VoiceStr = string(openai.VoiceAlloy)
+
+		Ω(speechFlags.ValidateFlags()).To(Succeed())
+		Ω(speechFlags.Format).To(Equal(openai.SpeechResponseFormatMp3))
+
+		speechFlags.FormatStr = "ogg"
+		err := speechFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("format must be"))
+
+		for _, format := range []openai.SpeechResponseFormat{
+			openai.SpeechResponseFormatOpus,
+			openai.SpeechResponseFormatAac,
+			openai.SpeechResponseFormatFlac,
+			openai.SpeechResponseFormatWav,
+			openai.SpeechResponseFormatPcm,
+		} {
+			speechFlags.FormatStr = string(format)
+			Ω(speechFlags.ValidateFlags()).To(Succeed())
+			Ω(speechFlags.Format).To(Equal(format))
+		}
+	})
+
+	It("should accept the newer voices for each model", func() {
+		speechFlags := cmd.NewSpeechFlags()
+		speechFlags.Speed = 1.0
+		speechFlags.ModelStr = string(openai.TTSModel1)
+
+		speechFlags.VoiceStr = "sage"
+		Ω(speechFlags.ValidateFlags()).To(Succeed())
+		Ω(speechFlags.Voice).To(Equal(openai.SpeechVoice("sage")))
+
+		speechFlags.VoiceStr = "marin"
+		err := speechFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("voice must be"))
+
+		speechFlags.ModelStr = string(openai.TTSModelGPT4oMini)
+		Ω(speechFlags.ValidateFlags()).To(Succeed())
+		Ω(speechFlags.Model).To(Equal(openai.TTSModelGPT4oMini))
+		Ω(speechFlags.Voice).To(Equal(openai.SpeechVoice("marin")))
+	})
+
+	It("should validate Instructions", func() {
+		speechFlags := cmd.NewSpeechFlags()
+		speechFlags.Speed = 1.0
+		speechFlags.VoiceStr = string(openai.VoiceCoral)
+		speechFlags.Instructions = "Speak like a calm radio host"
+
+		speechFlags.ModelStr = string(openai.TTSModel1HD)
+		err := speechFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("instructions are only supported"))
+
+		speechFlags.ModelStr = string(openai.TTSModelGPT4oMini)
+		Ω(speechFlags.ValidateFlags()).To(Succeed())
+	})
 })
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Speech Command", func() {
+	It("should name files with the extension of the format", func() {
+		f := NewSpeechFlags()
+		f.OutputPrefix = "tts"
+		f.Format = openai.SpeechResponseFormatFlac
+		Ω(getSpeechFileName(f)).To(Equal("tts-01.flac"))
+		Ω(getSpeechFileName(f)).To(Equal("tts-02.flac"))
+
+		f.Output = "reply.flac"
+		Ω(getSpeechFileName(f)).To(Equal("reply.flac"))
+	})
+
+	It("should have a voices command", func() {
+		speechCmd, _, err := NewRootCmd().Find([]string{"speech", "voices"})
+		Ω(err).ToNot(HaveOccurred())
+		Ω(speechCmd.Name()).To(Equal("voices"))
+	})
+
+	It("should sample the voices each model speaks", func() {
+		Ω(speechVoicesFor(openai.TTSModel1)).To(ContainElement(VoiceSage))
+		Ω(speechVoicesFor(openai.TTSModel1)).ToNot(ContainElement(VoiceMarin))
+		Ω(speechVoicesFor(openai.TTSModelGPT4oMini)).To(ContainElements(openai.VoiceAlloy, openai.VoiceBallad, VoiceMarin, VoiceCedar))
+		Ω(voiceSampleFileName("voices", openai.VoiceCoral, openai.SpeechResponseFormatWav)).To(Equal(filepath.Join("voices", "coral.wav")))
+	})
+})
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newSpeechVoicesCmd(rootFlags *RootFlags, speechFlags *SpeechFlags) *cobra.Command {
+	var outputDir, sampleText string
+	var cmd = &cobra.Command{
+		Use:   "voices",
+		Short: "Read a sample sentence in each voice",
+		Long:  "Read a sample sentence in each voice the model speaks, saving an audio file for each voice in a directory to audition them",
+		RunE:  speechVoicesCmdRunner(rootFlags, speechFlags, &outputDir, &sampleText),
+	}
+
+	AddOutputDirFlag(&outputDir, defaultVoicesDir, "Directory to save the voice samples in", cmd.Flags())
+	AddSampleTextFlag(&sampleText, cmd.Flags())
+
+	return cmd
+}
+
+func speechVoicesCmdRunner(rootFlags *RootFlags, speechFlags *SpeechFlags, outputDir *string, sampleText *string) func(cmd *cobra.Command, args []string) error {
+	return func(_ *cobra.Command, _ []string) error {
+		log.Debugf("speechVoicesCmd called")
+		err := speechFlags.ValidateFlags()
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+		if len([]rune(*sampleText)) > maxSpeechInputLength {
+			log.WithError(fmt.Errorf("text must be at most %d characters", maxSpeechInputLength)).Fatal()
+		}
+
+		client, err := setupOpenAIClient(rootFlags.apikey)
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+		if err := os.MkdirAll(*outputDir, 0755); err != nil {
+			log.WithError(err).Fatal()
+		}
+
+		if err := sendVoiceSamples(speechFlags, client, *outputDir, *sampleText); err != nil {
+			log.WithError(err).Fatal()
+		}
+		return nil
+	}
+}
+
+// sendVoiceSamples reads the text in each voice the model speaks, no more than --concurrency at a time,
+// saving each as the voice name in the directory
+func sendVoiceSamples(f *SpeechFlags, client *openai.Client, dir string, text string) error {
+	voices := speechVoicesFor(f.Model)
+	files := make([]string, len(voices))
+	errs := make([]error, len(voices))
+
+	var wg sync.WaitGroup
+	work := make(chan int)
+	for range min(f.Concurrency, len(voices)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				voiceFlags := *f
+				voiceFlags.Voice = voices[i]
+				audio, err := createSpeech(&voiceFlags, client, text)
+				if err == nil {
+					files[i] = voiceSampleFileName(dir, voices[i], f.Format)
+					err = os.WriteFile(files[i], audio, 0644)
+				}
+				errs[i] = err
+			}
+		}()
+	}
+	for i := range voices {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	failed := 0
+	for i, voice := range voices {
+		if errs[i] != nil {
+			failed++
+			_, _ = fmt.Fprintf(os.Stderr, "%s\n", ErrorFmt.Sprintf("%s failed: %v", voice, errs[i]))
+			continue
+		}
+		fmt.Printf("%s\n", files[i])
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d voices failed", failed, len(voices))
+	}
+	return nil
+}
+
+func voiceSampleFileName(dir string, voice openai.SpeechVoice, format openai.SpeechResponseFormat) string {
+	return filepath.Join(dir, fmt.Sprintf("%s.%s", voice, format))
+}
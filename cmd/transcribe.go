@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/sashabaranov/go-openai"
 	log "github.com/sirupsen/logrus"
@@ -20,12 +21,24 @@ func NewTranscriptionCmd(rootFlags *RootFlags) *cobra.Command {
 	}
 	setChatContext(cmd, chatContext)
 
-	AddModelFlag(&transcriptionFlags.model, cmd.PersistentFlags())
-	AddLanguageFlag(&transcriptionFlags.language, cmd.PersistentFlags())
+	AddTranscriptionModelFlag(&transcriptionFlags.Model, cmd.PersistentFlags())
+	AddLanguageFlag(&transcriptionFlags.Language, cmd.PersistentFlags())
+	AddTranscriptionFormatFlag(&transcriptionFlags.FormatStr, cmd.PersistentFlags())
+	AddTimestampsFlag(&transcriptionFlags.TimestampsStr, cmd.PersistentFlags())
+	AddMaxLineLengthFlag(&transcriptionFlags.MaxLineLength, cmd.PersistentFlags())
+	AddConcurrencyFlag(&transcriptionFlags.Concurrency, cmd.PersistentFlags())
+	AddSplitCommandFlag(&transcriptionFlags.SplitCommand, cmd.PersistentFlags())
+	AddSummarizeFlag(&transcriptionFlags.Summarize, cmd.PersistentFlags())
+	AddSummaryModelFlag(&transcriptionFlags.SummaryModel, cmd.PersistentFlags())
+	AddSummaryTemplateFlag(&transcriptionFlags.SummaryTemplate, cmd.PersistentFlags())
 	AddInputFileFlag(&transcriptionFlags.inputFiles, cmd.PersistentFlags())
+	AddInputDirFlag(&transcriptionFlags.InputDir, cmd.PersistentFlags())
+	AddGlobFlag(&transcriptionFlags.Glob, cmd.PersistentFlags())
+	AddOutputDirFlag(&transcriptionFlags.OutputDir, "", "Directory to write the transcripts to, instead of next to each recording", cmd.PersistentFlags())
+	AddTranscriptionJSONFlag(&transcriptionFlags.JSON, cmd.PersistentFlags())
 	AddInitialSystemMessageFlag(&transcriptionFlags.initialSystemMessage, cmd.PersistentFlags())
 	_ = cmd.MarkPersistentFlagRequired(FlagApiKey)
-	_ = cmd.MarkPersistentFlagRequired(FlagInputFile)
+	cmd.MarkFlagsOneRequired(FlagInputFile, FlagInputDir)
 
 	return cmd
 }
@@ -39,6 +52,7 @@ func transcribeCmdRunner(rootFlags *RootFlags, transcriptionFlags *Transcription
 		}
 
 		chatContext.InteractiveSession = detectTerminal()
+		chatContext.RenderMarkdown = detectRenderMarkdown(false, false, chatContext)
 		if chatContext.InteractiveSession {
 			printTranscriptionBanner(transcriptionFlags)
 		}
@@ -47,7 +61,12 @@ func transcribeCmdRunner(rootFlags *RootFlags, transcriptionFlags *Transcription
 			log.WithError(err).Fatal()
 		}
 
-		if err := sendTranscriptionMessages(transcriptionFlags, chatContext, client); err != nil {
+		if transcriptionFlags.isBatch() {
+			err = runTranscriptionBatch(transcriptionFlags, chatContext, client)
+		} else {
+			err = sendTranscriptionMessages(transcriptionFlags, chatContext, client)
+		}
+		if err != nil {
 			log.WithError(err).Fatal()
 		}
 		return nil
@@ -56,37 +75,98 @@ func transcribeCmdRunner(rootFlags *RootFlags, transcriptionFlags *Transcription
 
 func printTranscriptionBanner(f *TranscriptionFlags) {
 	TitleFmt.Printf("ChatGPT CLI v%s\n", version)
-	fmt.Printf("Transcription Model: %s\n", f.model)
+	if f.Translate {
+		fmt.Printf("Translation Model: %s\n", f.Model)
+	} else {
+		fmt.Printf("Transcription Model: %s\n", f.Model)
+	}
+	if f.Language != "" {
+		fmt.Printf("Language: %s\n", f.Language)
+	}
+	fmt.Printf("Format: %s\n", f.Format)
+	if f.Summarize {
+		fmt.Printf("Summary Model: %s, template: %s\n", f.SummaryModel, f.SummaryTemplate)
+	}
 	fmt.Printf("- Press TAB after entering a message to send.\n")
 	fmt.Printf("- Press TAB or CTRL+C with a blank message to terminate the session without sending.\n")
 }
 
-// sendVisionMessages sends messages to ChatGPT and prints the response
+// sendTranscriptionMessages transcribes or translates each input file in turn, printing text and writing the other
+// formats to <name>.<ext>
 func sendTranscriptionMessages(f *TranscriptionFlags, chatContext *ChatContext, client *openai.Client) error {
-	mySpinner := newSpinner()
-
 	for _, file := range f.inputFiles {
-		successSpinner, _ := mySpinner.Start("Sending to ChatGPT, please wait...")
-
-		resp, err := client.CreateTranscription(
-			context.Background(),
-			openai.AudioRequest{
-				Prompt:   f.initialSystemMessage,
-				Language: f.language,
-				Model:    openai.Whisper1,
-				FilePath: file,
-			},
-		)
+		resp, err := transcribeFile(f, client, file, true)
 		if err != nil {
-			successSpinner.Fail(err.Error())
 			return err
 		}
-		successSpinner.Success()
 
-		if chatContext.InteractiveSession {
-			AiFmt.Printf("\nChatGPT response:\n")
+		output, err := formatTranscription(resp, f.Format, f.MaxLineLength)
+		if err != nil {
+			return err
+		}
+
+		if f.Format == openai.AudioResponseFormatText {
+			if chatContext.InteractiveSession {
+				AiFmt.Printf("\nChatGPT response:\n")
+			}
+			fmt.Printf("%s", output)
+		} else {
+			fileName := f.outputFileName(file)
+			if err := os.WriteFile(fileName, output, 0644); err != nil {
+				return err
+			}
+			fmt.Printf("%s\n", fileName)
+		}
+
+		if f.Summarize {
+			sessionFile, err := summarizeTranscript(f, chatContext, client, file, transcriptText(resp, f.Format))
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "Ask follow-up questions with: chatgpt-cli chat --%s %s\n", FlagSessionFile, sessionFile)
 		}
-		fmt.Printf("%s\n", resp.Text)
 	}
 	return nil
 }
+
+// transcribeFile sends a file in one request, or in chunks when it is too large to upload.
+// The spinner is shown for single requests, as chunks print their progress.
+func transcribeFile(f *TranscriptionFlags, client *openai.Client, file string, showSpinner bool) (openai.AudioResponse, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return openai.AudioResponse{}, err
+	}
+	if info.Size() >= audioChunkSize {
+		return transcribeLargeFile(f, client, file)
+	}
+
+	req := openai.AudioRequest{
+		Prompt:                 f.initialSystemMessage,
+		Language:               f.Language,
+		Model:                  f.Model,
+		FilePath:               file,
+		Format:                 f.Format,
+		TimestampGranularities: f.Timestamps,
+	}
+	if !showSpinner {
+		return createTranscription(f, client, req)
+	}
+
+	mySpinner := newSpinner()
+	successSpinner, _ := mySpinner.Start("Sending to ChatGPT, please wait...")
+	resp, err := createTranscription(f, client, req)
+	if err != nil {
+		successSpinner.Fail(err.Error())
+		return openai.AudioResponse{}, err
+	}
+	successSpinner.Success()
+	return resp, nil
+}
+
+// createTranscription calls the translation endpoint for translate-audio and the transcription endpoint otherwise
+func createTranscription(f *TranscriptionFlags, client *openai.Client, req openai.AudioRequest) (openai.AudioResponse, error) {
+	if f.Translate {
+		return client.CreateTranslation(context.Background(), req)
+	}
+	return client.CreateTranscription(context.Background(), req)
+}
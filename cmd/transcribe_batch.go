@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pterm/pterm"
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	transcriptionDone    = "done"
+	transcriptionSkipped = "skipped"
+	transcriptionFailed  = "failed"
+)
+
+// audioFileExtensions are the formats the API accepts, found in --dir when there is no --glob
+var audioFileExtensions = []string{".flac", ".m4a", ".mp3", ".mp4", ".mpeg", ".mpga", ".ogg", ".wav", ".webm"}
+
+// TranscriptionRecord is the outcome of transcribing one file, printed with --json
+type TranscriptionRecord struct {
+	File    string `json:"file"`
+	Status  string `json:"status"`
+	Output  string `json:"output,omitempty"`
+	Session string `json:"session,omitempty"`
+	Text    string `json:"text,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// transcriptionInputFiles returns the --file recordings, then those in --dir matching --glob, in name order
+func transcriptionInputFiles(f *TranscriptionFlags) ([]string, error) {
+	files := slices.Clone(f.inputFiles)
+	if f.InputDir == "" {
+		return files, nil
+	}
+
+	entries, err := os.ReadDir(f.InputDir)
+	if err != nil {
+		return nil, err
+	}
+	found := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matched := slices.Contains(audioFileExtensions, strings.ToLower(filepath.Ext(entry.Name())))
+		if f.Glob != "" {
+			matched, _ = filepath.Match(f.Glob, entry.Name())
+		}
+		if matched {
+			files = append(files, filepath.Join(f.InputDir, entry.Name()))
+			found++
+		}
+	}
+	if found == 0 {
+		return nil, fmt.Errorf("no recordings found in %s", f.InputDir)
+	}
+	return files, nil
+}
+
+// runTranscriptionBatch transcribes the files, no more than --concurrency at a time, writing each transcript to a file,
+// and skipping files that already have one. Then prints a table of the outcomes, or the records as JSON.
+func runTranscriptionBatch(f *TranscriptionFlags, chatContext *ChatContext, client *openai.Client) error {
+	files, err := transcriptionInputFiles(f)
+	if err != nil {
+		return err
+	}
+	if err := checkTranscriptionOutputs(f, files); err != nil {
+		return err
+	}
+	if f.OutputDir != "" {
+		if err := os.MkdirAll(f.OutputDir, 0755); err != nil {
+			return fmt.Errorf("unable to create directory: %w", err)
+		}
+	}
+
+	// the files are already sent --concurrency at a time, so the chunks of a large file are sent one at a time
+	fileFlags := *f
+	fileFlags.Concurrency = 1
+	fileContext := *chatContext
+	fileContext.SuppressResponse = true
+
+	records := make([]TranscriptionRecord, len(files))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	work := make(chan int)
+	for range min(f.Concurrency, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				records[i] = transcribeBatchFile(&fileFlags, &fileContext, client, files[i])
+
+				mu.Lock()
+				done++
+				record := records[i]
+				if record.Status == transcriptionFailed {
+					_, _ = fmt.Fprintf(os.Stderr, "%s\n", ErrorFmt.Sprintf("[%d/%d] failed: %s: %s", done, len(files), record.File, record.Error))
+				} else {
+					_, _ = fmt.Fprintf(os.Stderr, "[%d/%d] %s: %s\n", done, len(files), record.Status, record.File)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range files {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	if f.JSON {
+		output, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return fmt.Errorf("JSON encode error: %w", err)
+		}
+		fmt.Printf("%s\n", output)
+	} else if err := printTranscriptionSummary(records); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, record := range records {
+		if record.Status == transcriptionFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed", failed, len(records))
+	}
+	return nil
+}
+
+// checkTranscriptionOutputs makes sure no two files are transcribed to the same output, such as recordings with
+// the same name from --file and --dir, written to the --output-dir
+func checkTranscriptionOutputs(f *TranscriptionFlags, files []string) error {
+	inputs := map[string]string{}
+	for _, file := range files {
+		output := filepath.Clean(f.outputFileName(file))
+		if other, ok := inputs[output]; ok {
+			return fmt.Errorf("%s and %s would both be transcribed to %s", other, file, output)
+		}
+		inputs[output] = file
+	}
+	return nil
+}
+
+// transcribeBatchFile transcribes one file to its output file, and summarizes it when asked. Files with an output
+// file, and a session file when summarizing, are skipped; when only the session file is missing, the notes are
+// made from the output file.
+func transcribeBatchFile(f *TranscriptionFlags, chatContext *ChatContext, client *openai.Client, file string) TranscriptionRecord {
+	record := TranscriptionRecord{File: file, Output: f.outputFileName(file)}
+	failed := func(err error) TranscriptionRecord {
+		record.Status = transcriptionFailed
+		record.Output = ""
+		record.Error = err.Error()
+		return record
+	}
+
+	if _, err := os.Stat(record.Output); err == nil {
+		if !f.Summarize {
+			record.Status = transcriptionSkipped
+			return record
+		}
+		if _, err := os.Stat(f.sessionFileName(file)); err == nil {
+			record.Status = transcriptionSkipped
+			record.Session = f.sessionFileName(file)
+			return record
+		}
+		if record.Text, err = readTranscript(record.Output, f.Format); err != nil {
+			return failed(err)
+		}
+	} else {
+		resp, err := transcribeFile(f, client, file, false)
+		if err != nil {
+			return failed(err)
+		}
+		output, err := formatTranscription(resp, f.Format, f.MaxLineLength)
+		if err != nil {
+			return failed(err)
+		}
+		if err := os.WriteFile(record.Output, output, 0644); err != nil {
+			return failed(err)
+		}
+		record.Text = transcriptText(resp, f.Format)
+	}
+
+	if f.Summarize {
+		var err error
+		if record.Session, err = summarizeTranscript(f, chatContext, client, file, record.Text); err != nil {
+			// the transcript was written, so only the notes need to be made again, with chat
+			record.Status = transcriptionFailed
+			record.Error = fmt.Sprintf("summarize: %v", err)
+			return record
+		}
+	}
+	record.Status = transcriptionDone
+	return record
+}
+
+// readTranscript reads the words of a transcript written in the format
+func readTranscript(fileName string, format openai.AudioResponseFormat) (string, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return "", err
+	}
+	resp := openai.AudioResponse{Text: string(data)}
+	if format == openai.AudioResponseFormatJSON || format == openai.AudioResponseFormatVerboseJSON {
+		resp = openai.AudioResponse{}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return "", fmt.Errorf("%s: JSON decode error: %w", fileName, err)
+		}
+	}
+	return transcriptText(resp, format), nil
+}
+
+// printTranscriptionSummary prints a table of the outcome of each file, then the totals
+func printTranscriptionSummary(records []TranscriptionRecord) error {
+	data := pterm.TableData{{"File", "Status", "Output", "Error"}}
+	counts := map[string]int{}
+	for _, record := range records {
+		output := record.Output
+		if record.Session != "" {
+			output += ", " + record.Session
+		}
+		data = append(data, []string{record.File, record.Status, output, record.Error})
+		counts[record.Status]++
+	}
+	if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "%d transcribed, %d skipped, %d failed\n",
+		counts[transcriptionDone], counts[transcriptionSkipped], counts[transcriptionFailed])
+	return nil
+}
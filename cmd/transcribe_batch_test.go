@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transcription Batch", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		for _, name := range []string{"b.m4a", "a.WAV", "notes.txt", "c.mp3"} {
+			Ω(os.WriteFile(filepath.Join(dir, name), []byte("audio"), 0644)).To(Succeed())
+		}
+		Ω(os.Mkdir(filepath.Join(dir, "old.mp3"), 0755)).To(Succeed())
+	})
+
+	It("should find the recordings in the dir", func() {
+		f := NewTranscriptionFlags()
+		f.inputFiles = []string{"standup.m4a"}
+		f.InputDir = dir
+		Ω(transcriptionInputFiles(f)).To(Equal([]string{"standup.m4a",
+			filepath.Join(dir, "a.WAV"), filepath.Join(dir, "b.m4a"), filepath.Join(dir, "c.mp3")}))
+
+		f.Glob = "*.m4a"
+		Ω(transcriptionInputFiles(f)).To(Equal([]string{"standup.m4a", filepath.Join(dir, "b.m4a")}))
+
+		f.Glob = "*.ogg"
+		_, err := transcriptionInputFiles(f)
+		Ω(err).To(MatchError(ContainSubstring("no recordings found")))
+	})
+
+	It("should write to the output dir", func() {
+		f := NewTranscriptionFlags()
+		Ω(f.isBatch()).To(BeFalse())
+
+		f.OutputDir = "transcripts"
+		Ω(f.isBatch()).To(BeTrue())
+		Ω(f.ValidateFlags()).To(Succeed())
+		Ω(f.outputFileName("recordings/b.m4a")).To(Equal(filepath.Join("transcripts", "b.txt")))
+	})
+
+	It("should skip files that already have an output", func() {
+		f := NewTranscriptionFlags()
+		f.OutputDir = dir
+		f.FormatStr = "srt"
+		Ω(f.ValidateFlags()).To(Succeed())
+		Ω(os.WriteFile(filepath.Join(dir, "b.srt"), []byte("1\n"), 0644)).To(Succeed())
+
+		record := transcribeBatchFile(f, NewChatContext(), nil, filepath.Join("recordings", "b.m4a"))
+		Ω(record).To(Equal(TranscriptionRecord{
+			File:   filepath.Join("recordings", "b.m4a"),
+			Status: transcriptionSkipped,
+			Output: filepath.Join(dir, "b.srt"),
+		}))
+	})
+
+	It("should only skip files with notes when summarizing", func() {
+		f := NewTranscriptionFlags()
+		f.OutputDir = dir
+		f.Summarize = true
+		Ω(f.ValidateFlags()).To(Succeed())
+		Ω(os.WriteFile(filepath.Join(dir, "b.txt"), []byte("Hello\n"), 0644)).To(Succeed())
+		Ω(os.WriteFile(filepath.Join(dir, "b.session.json"), []byte("{}"), 0644)).To(Succeed())
+
+		record := transcribeBatchFile(f, NewChatContext(), nil, filepath.Join("recordings", "b.m4a"))
+		Ω(record).To(Equal(TranscriptionRecord{
+			File:    filepath.Join("recordings", "b.m4a"),
+			Status:  transcriptionSkipped,
+			Output:  filepath.Join(dir, "b.txt"),
+			Session: filepath.Join(dir, "b.session.json"),
+		}))
+	})
+
+	It("should read the words of a transcript that was already written", func() {
+		srt := filepath.Join(dir, "b.srt")
+		Ω(os.WriteFile(srt, []byte("1\n00:00:00,000 --> 00:00:01,000\nHello\n\n2\n00:00:01,000 --> 00:00:02,000\nthere\n"), 0644)).To(Succeed())
+		Ω(readTranscript(srt, openai.AudioResponseFormatSRT)).To(Equal("Hello there"))
+
+		verbose := filepath.Join(dir, "b.json")
+		Ω(os.WriteFile(verbose, []byte(`{"text": " Hello there", "duration": 2}`), 0644)).To(Succeed())
+		Ω(readTranscript(verbose, openai.AudioResponseFormatVerboseJSON)).To(Equal("Hello there"))
+	})
+
+	It("should not transcribe two files to the same output", func() {
+		f := NewTranscriptionFlags()
+		f.OutputDir = "transcripts"
+		Ω(f.ValidateFlags()).To(Succeed())
+		Ω(checkTranscriptionOutputs(f, []string{"monday/standup.m4a", "tuesday/review.m4a"})).To(Succeed())
+
+		err := checkTranscriptionOutputs(f, []string{"monday/standup.m4a", "tuesday/standup.wav"})
+		Ω(err).To(MatchError("monday/standup.m4a and tuesday/standup.wav would both be transcribed to " + filepath.Join("transcripts", "standup.txt")))
+	})
+
+	It("should fail the batch when any file fails", func() {
+		f := NewTranscriptionFlags()
+		f.inputFiles = []string{filepath.Join(dir, "missing.m4a")}
+		f.OutputDir = filepath.Join(dir, "transcripts")
+		f.JSON = true
+		Ω(f.ValidateFlags()).To(Succeed())
+
+		// the records and progress are printed, so they are kept out of the test output
+		stdout, stderr := os.Stdout, os.Stderr
+		output, err := os.Create(filepath.Join(dir, "output.txt"))
+		Ω(err).ToNot(HaveOccurred())
+		os.Stdout, os.Stderr = output, output
+		err = runTranscriptionBatch(f, NewChatContext(), nil)
+		os.Stdout, os.Stderr = stdout, stderr
+		Ω(output.Close()).To(Succeed())
+
+		Ω(err).To(MatchError("1 of 1 files failed"))
+		Ω(os.ReadFile(output.Name())).To(ContainSubstring(`"status": "failed"`))
+	})
+
+	It("should fail files that can't be read", func() {
+		f := NewTranscriptionFlags()
+		f.OutputDir = dir
+		Ω(f.ValidateFlags()).To(Succeed())
+
+		record := transcribeBatchFile(f, NewChatContext(), nil, filepath.Join(dir, "missing.m4a"))
+		Ω(record.Status).To(Equal(transcriptionFailed))
+		Ω(record.Output).To(BeEmpty())
+		Ω(record.Error).To(ContainSubstring("no such file"))
+	})
+})
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// maxPromptTail is how much of the previous chunk's transcript is sent as the prompt for the next, in characters
+const maxPromptTail = 200
+
+// transcribeLargeFile splits a recording too large to upload, transcribes the chunks, and stitches them into one response
+func transcribeLargeFile(f *TranscriptionFlags, client *openai.Client, file string) (openai.AudioResponse, error) {
+	splitter, err := audioSplitterFor(file, f.SplitCommand)
+	if err != nil {
+		return openai.AudioResponse{}, err
+	}
+
+	dir, err := os.MkdirTemp("", "chatgpt-cli-stt-")
+	if err != nil {
+		return openai.AudioResponse{}, fmt.Errorf("unable to create directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	chunks, err := splitter.Split(file, audioChunkSize, dir)
+	if err != nil {
+		return openai.AudioResponse{}, err
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "%s is larger than 24 MB, transcribing it in %d chunks\n", file, len(chunks))
+
+	responses, err := transcribeChunks(f, client, chunks)
+	if err != nil {
+		return openai.AudioResponse{}, err
+	}
+	resp := stitchTranscriptions(chunks, responses)
+	if f.Format == openai.AudioResponseFormatSRT || f.Format == openai.AudioResponseFormatVTT {
+		resp.Text = renderSubtitles(resp, f.Format)
+	}
+	return resp, nil
+}
+
+// chunkRequestFormat is the format to request each chunk in, verbose_json wherever the model has it, for the
+// timestamps needed to stitch the chunks together
+func chunkRequestFormat(f *TranscriptionFlags) openai.AudioResponseFormat {
+	if f.Model == openai.Whisper1 {
+		return openai.AudioResponseFormatVerboseJSON
+	}
+	return openai.AudioResponseFormatJSON
+}
+
+// transcribeChunks transcribes the chunks in --concurrency runs of neighbouring chunks. Within a run, each chunk is
+// sent after the one before, with the tail of its transcript as the prompt, so speech cut at a chunk boundary
+// carries on in the same style and spelling.
+func transcribeChunks(f *TranscriptionFlags, client *openai.Client, chunks []AudioChunk) ([]openai.AudioResponse, error) {
+	responses := make([]openai.AudioResponse, len(chunks))
+	errs := make([]error, len(chunks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	for _, run := range chunkRuns(len(chunks), f.Concurrency) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			previous := ""
+			for i := run[0]; i < run[1]; i++ {
+				req := openai.AudioRequest{
+					Prompt:   chunkPrompt(f.initialSystemMessage, previous),
+					Language: f.Language,
+					Model:    f.Model,
+					FilePath: chunks[i].File,
+					Format:   chunkRequestFormat(f),
+				}
+				// srt and vtt are rendered from the segments, so only verbose_json asks for other timestamps
+				if f.Format == openai.AudioResponseFormatVerboseJSON {
+					req.TimestampGranularities = f.Timestamps
+				}
+				responses[i], errs[i] = createTranscription(f, client, req)
+				previous = responses[i].Text
+
+				mu.Lock()
+				done++
+				if errs[i] != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "%s\n", ErrorFmt.Sprintf("[%d/%d] chunk %d failed: %v", done, len(chunks), i+1, errs[i]))
+				} else {
+					_, _ = fmt.Fprintf(os.Stderr, "[%d/%d] chunk %d done\n", done, len(chunks), i+1)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// chunkRuns divides count chunks into at most concurrency runs of neighbouring chunks, as [start, end) pairs
+func chunkRuns(count int, concurrency int) [][2]int {
+	runs := min(max(concurrency, 1), count)
+	var result [][2]int
+	start := 0
+	for i := range runs {
+		end := start + count/runs
+		if i < count%runs {
+			end++
+		}
+		result = append(result, [2]int{start, end})
+		start = end
+	}
+	return result
+}
+
+// chunkPrompt follows the --system-message with the end of the previous chunk's transcript, starting at a word
+func chunkPrompt(systemMessage string, previous string) string {
+	tail := strings.TrimSpace(previous)
+	if runes := []rune(tail); len(runes) > maxPromptTail {
+		tail = string(runes[len(runes)-maxPromptTail:])
+		if space := strings.IndexAny(tail, " \n"); space >= 0 {
+			tail = tail[space+1:]
+		}
+	}
+	return strings.TrimSpace(systemMessage + " " + tail)
+}
+
+// stitchTranscriptions joins the chunk transcripts into one, moving each chunk's timestamps to where it starts
+// in the recording
+func stitchTranscriptions(chunks []AudioChunk, responses []openai.AudioResponse) openai.AudioResponse {
+	var stitched openai.AudioResponse
+	var texts []string
+	start := 0.0
+	for i, resp := range responses {
+		if chunks[i].Start >= 0 {
+			start = chunks[i].Start
+		}
+		if i == 0 {
+			stitched.Task, stitched.Language = resp.Task, resp.Language
+		}
+
+		for _, segment := range resp.Segments {
+			segment.ID = len(stitched.Segments)
+			segment.Start += start
+			segment.End += start
+			stitched.Segments = append(stitched.Segments, segment)
+		}
+		for _, word := range resp.Words {
+			word.Start += start
+			word.End += start
+			stitched.Words = append(stitched.Words, word)
+		}
+		if text := strings.TrimSpace(resp.Text); text != "" {
+			texts = append(texts, text)
+		}
+
+		stitched.Duration = start + resp.Duration
+		start = stitched.Duration
+	}
+	stitched.Text = strings.Join(texts, " ")
+	return stitched
+}
+
+// renderSubtitles writes the segments of a response as SRT or VTT cues
+func renderSubtitles(resp openai.AudioResponse, format openai.AudioResponseFormat) string {
+	var sb strings.Builder
+	separator := ","
+	if format == openai.AudioResponseFormatVTT {
+		sb.WriteString("WEBVTT\n\n")
+		separator = "."
+	}
+	for i, segment := range resp.Segments {
+		if format == openai.AudioResponseFormatSRT {
+			sb.WriteString(fmt.Sprintf("%d\n", i+1))
+		}
+		sb.WriteString(fmt.Sprintf("%s --> %s\n%s\n\n",
+			subtitleTimestamp(segment.Start, separator), subtitleTimestamp(segment.End, separator), strings.TrimSpace(segment.Text)))
+	}
+	return sb.String()
+}
+
+// subtitleTimestamp formats seconds as hours:minutes:seconds, then the separator and milliseconds
+func subtitleTimestamp(seconds float64, separator string) string {
+	millis := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", millis/3600000, millis/60000%60, millis/1000%60, separator, millis%1000)
+}
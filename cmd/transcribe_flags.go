@@ -1,16 +1,153 @@
 package cmd
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// transcription models added after the go-openai constants
+const (
+	TranscriptionModelGpt4o     = "gpt-4o-transcribe"
+	TranscriptionModelGpt4oMini = "gpt-4o-mini-transcribe"
+)
+
 type TranscriptionFlags struct {
-	model                string
-	language             string
+	Model    string
+	Language string
+
+	FormatStr string
+	Format    openai.AudioResponseFormat
+
+	TimestampsStr []string
+	Timestamps    []openai.TranscriptionTimestampGranularity
+
+	MaxLineLength        int
 	initialSystemMessage string
 	inputFiles           []string
+
+	// Concurrency and SplitCommand are used for recordings too large to upload, which are split into chunks
+	Concurrency  int
+	SplitCommand string
+
+	// Summarize sends each transcript to SummaryModel for notes, following SummaryTemplate
+	Summarize       bool
+	SummaryModel    string
+	SummaryTemplate string
+
+	// InputDir, Glob, OutputDir, and JSON transcribe many files at once, --concurrency at a time
+	InputDir  string
+	Glob      string
+	OutputDir string
+	JSON      bool
+
+	// Translate sends the audio to the translation endpoint, which answers in English
+	Translate bool
 }
 
 func NewTranscriptionFlags() *TranscriptionFlags {
-	return &TranscriptionFlags{model: "whisper-1"}
+	return &TranscriptionFlags{
+		Model:       defaultTranscriptionModel,
+		FormatStr:   defaultTranscriptionFormat,
+		Concurrency: defaultConcurrency,
+
+		SummaryModel:    defaultModel,
+		SummaryTemplate: defaultSummaryTemplate,
+	}
+}
+
+// NewTranslationFlags creates the flags of translate-audio, which mirror those of transcribe
+func NewTranslationFlags() *TranscriptionFlags {
+	f := NewTranscriptionFlags()
+	f.Translate = true
+	return f
 }
 
 func (f *TranscriptionFlags) ValidateFlags() error {
+	if f.InputDir != "" {
+		if info, err := os.Stat(f.InputDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("dir not found: %s", f.InputDir)
+		}
+	}
+	if f.Glob != "" {
+		if f.InputDir == "" {
+			return fmt.Errorf("glob requires --%s", FlagInputDir)
+		}
+		if _, err := filepath.Match(f.Glob, ""); err != nil {
+			return fmt.Errorf("glob is not a valid pattern: %w", err)
+		}
+	}
+
+	if f.Translate {
+		if f.Model != openai.Whisper1 {
+			return fmt.Errorf("model must be whisper-1, for translation")
+		}
+		if f.Language != "" {
+			return fmt.Errorf("language is not supported for translation, which is always to English")
+		}
+		if len(f.TimestampsStr) > 0 {
+			return fmt.Errorf("timestamps are not supported for translation")
+		}
+	}
+
+	switch f.Model {
+	case openai.Whisper1, TranscriptionModelGpt4o, TranscriptionModelGpt4oMini:
+		// these are fine
+	default:
+		return fmt.Errorf("model must be one of whisper-1, gpt-4o-transcribe, or gpt-4o-mini-transcribe")
+	}
+
+	if f.Language != "" {
+		f.Language = strings.ToLower(f.Language)
+		if !iso6391Codes[f.Language] {
+			return fmt.Errorf("language must be an ISO-639-1 code, such as en, de, or ja")
+		}
+	}
+
+	f.Format = openai.AudioResponseFormat(f.FormatStr)
+	if _, ok := transcriptionFileExtensions[f.Format]; !ok {
+		return fmt.Errorf("transcription-format must be one of text, json, verbose_json, srt, or vtt")
+	}
+	if f.Model != openai.Whisper1 && f.Format != openai.AudioResponseFormatText && f.Format != openai.AudioResponseFormatJSON {
+		return fmt.Errorf("transcription-format must be text or json, for %s", f.Model)
+	}
+
+	f.Timestamps = nil
+	for _, timestamp := range f.TimestampsStr {
+		switch granularity := openai.TranscriptionTimestampGranularity(strings.ToLower(timestamp)); granularity {
+		case openai.TranscriptionTimestampGranularitySegment, openai.TranscriptionTimestampGranularityWord:
+			f.Timestamps = append(f.Timestamps, granularity)
+		default:
+			return fmt.Errorf("timestamps must be segment, word, or both")
+		}
+	}
+	if len(f.Timestamps) > 0 && f.Format != openai.AudioResponseFormatVerboseJSON {
+		return fmt.Errorf("timestamps require the verbose_json transcription-format")
+	}
+
+	if f.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+
+	if f.Summarize {
+		if _, err := summaryInstructions(f.SummaryTemplate); err != nil {
+			return err
+		}
+	}
+
+	if f.MaxLineLength < 0 {
+		return fmt.Errorf("max-line-length must not be negative")
+	}
+	if f.MaxLineLength > 0 && f.Format != openai.AudioResponseFormatSRT && f.Format != openai.AudioResponseFormatVTT {
+		return fmt.Errorf("max-line-length requires the srt or vtt transcription-format")
+	}
 	return nil
 }
+
+// isBatch reports if the files are transcribed at the same time, writing every transcript to a file
+func (f *TranscriptionFlags) isBatch() bool {
+	return f.InputDir != "" || f.OutputDir != "" || f.JSON
+}
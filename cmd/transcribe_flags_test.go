@@ -0,0 +1,152 @@
+package cmd_test
+
+import (
+	"github.com/duanemay/chatgpt-cli/cmd"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sashabaranov/go-openai"
+)
+
+var _ = Describe("Transcription Flags", func() {
+	It("should succeed on New Flags", func() {
+		transcriptionFlags := cmd.NewTranscriptionFlags()
+		Ω(transcriptionFlags.ValidateFlags()).To(Succeed())
+		Ω(transcriptionFlags.Model).To(Equal(openai.Whisper1))
+	})
+
+	It("should validate Model", func() {
+		transcriptionFlags := cmd.NewTranscriptionFlags()
+		transcriptionFlags.Model = string(openai.GPT5ChatLatest)
+		err := transcriptionFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("model must be"))
+
+		for _, model := range []string{openai.Whisper1, cmd.TranscriptionModelGpt4o, cmd.TranscriptionModelGpt4oMini} {
+			transcriptionFlags.Model = model
+			Ω(transcriptionFlags.ValidateFlags()).To(Succeed())
+		}
+	})
+
+	It("should validate Language", func() {
+		transcriptionFlags := cmd.NewTranscriptionFlags()
+		transcriptionFlags.Language = "english"
+		err := transcriptionFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("ISO-639-1"))
+
+		transcriptionFlags.Language = "xx"
+		Ω(transcriptionFlags.ValidateFlags()).Error().To(HaveOccurred())
+
+		transcriptionFlags.Language = "DE"
+		Ω(transcriptionFlags.ValidateFlags()).To(Succeed())
+		Ω(transcriptionFlags.Language).To(Equal("de"))
+	})
+	It("should validate Format", func() {
+		transcriptionFlags := cmd.NewTranscriptionFlags()
+		transcriptionFlags.FormatStr = "docx"
+		err := transcriptionFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("format must be"))
+
+		transcriptionFlags.FormatStr = "srt"
+		Ω(transcriptionFlags.ValidateFlags()).To(Succeed())
+		Ω(transcriptionFlags.Format).To(Equal(openai.AudioResponseFormatSRT))
+
+		transcriptionFlags.Model = cmd.TranscriptionModelGpt4o
+		err = transcriptionFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("text or json"))
+	})
+
+	It("should validate Timestamps", func() {
+		transcriptionFlags := cmd.NewTranscriptionFlags()
+		transcriptionFlags.TimestampsStr = []string{"word"}
+		err := transcriptionFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("verbose_json"))
+
+		transcriptionFlags.FormatStr = "verbose_json"
+		transcriptionFlags.TimestampsStr = []string{"sentence"}
+		Ω(transcriptionFlags.ValidateFlags()).Error().To(HaveOccurred())
+
+		transcriptionFlags.TimestampsStr = []string{"segment", "word"}
+		Ω(transcriptionFlags.ValidateFlags()).To(Succeed())
+		Ω(transcriptionFlags.Timestamps).To(Equal([]openai.TranscriptionTimestampGranularity{
+			openai.TranscriptionTimestampGranularitySegment, openai.TranscriptionTimestampGranularityWord}))
+	})
+
+	It("should validate Max Line Length", func() {
+		transcriptionFlags := cmd.NewTranscriptionFlags()
+		transcriptionFlags.MaxLineLength = 42
+		err := transcriptionFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("srt or vtt"))
+
+		transcriptionFlags.FormatStr = "vtt"
+		Ω(transcriptionFlags.ValidateFlags()).To(Succeed())
+
+		transcriptionFlags.MaxLineLength = -1
+		Ω(transcriptionFlags.ValidateFlags()).Error().To(HaveOccurred())
+	})
+	It("should validate Concurrency", func() {
+		transcriptionFlags := cmd.NewTranscriptionFlags()
+		Ω(transcriptionFlags.Concurrency).To(Equal(3))
+		transcriptionFlags.Concurrency = 0
+		err := transcriptionFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("concurrency"))
+	})
+
+	It("should validate Summary Template", func() {
+		transcriptionFlags := cmd.NewTranscriptionFlags()
+		transcriptionFlags.SummaryTemplate = "retro"
+		Ω(transcriptionFlags.ValidateFlags()).To(Succeed())
+
+		transcriptionFlags.Summarize = true
+		err := transcriptionFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("template must be"))
+
+		transcriptionFlags.SummaryTemplate = "standup"
+		Ω(transcriptionFlags.ValidateFlags()).To(Succeed())
+	})
+
+	It("should validate Dir and Glob", func() {
+		transcriptionFlags := cmd.NewTranscriptionFlags()
+		transcriptionFlags.Glob = "*.m4a"
+		err := transcriptionFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("glob requires"))
+
+		transcriptionFlags.InputDir = "no-such-recordings"
+		err = transcriptionFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("dir not found"))
+
+		transcriptionFlags.InputDir = GinkgoT().TempDir()
+		Ω(transcriptionFlags.ValidateFlags()).To(Succeed())
+
+		transcriptionFlags.Glob = "[m4a"
+		Ω(transcriptionFlags.ValidateFlags()).Error().To(HaveOccurred())
+	})
+
+	It("should validate Translation Flags", func() {
+		translationFlags := cmd.NewTranslationFlags()
+		Ω(translationFlags.ValidateFlags()).To(Succeed())
+
+		translationFlags.Model = cmd.TranscriptionModelGpt4o
+		err := translationFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("whisper-1"))
+
+		translationFlags.Model = openai.Whisper1
+		translationFlags.Language = "de"
+		err = translationFlags.ValidateFlags()
+		Ω(err).Error().To(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("language"))
+
+		translationFlags.Language = ""
+		translationFlags.FormatStr = "srt"
+		Ω(translationFlags.ValidateFlags()).To(Succeed())
+	})
+})
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// transcriptionFileExtensions maps each response format to the extension of the file it is written to
+var transcriptionFileExtensions = map[openai.AudioResponseFormat]string{
+	openai.AudioResponseFormatText:        "txt",
+	openai.AudioResponseFormatJSON:        "json",
+	openai.AudioResponseFormatVerboseJSON: "json",
+	openai.AudioResponseFormatSRT:         "srt",
+	openai.AudioResponseFormatVTT:         "vtt",
+}
+
+// transcriptionFileName names the output for an input file, such as meeting.srt for meeting.m4a
+func transcriptionFileName(inputFile string, format openai.AudioResponseFormat) string {
+	return strings.TrimSuffix(inputFile, filepath.Ext(inputFile)) + "." + transcriptionFileExtensions[format]
+}
+
+// outputPath moves an input file to the --output-dir, when there is one
+func (f *TranscriptionFlags) outputPath(inputFile string) string {
+	if f.OutputDir == "" {
+		return inputFile
+	}
+	return filepath.Join(f.OutputDir, filepath.Base(inputFile))
+}
+
+// outputFileName names the output for an input file, marking translations as English, such as meeting.en.srt,
+// in the --output-dir when there is one
+func (f *TranscriptionFlags) outputFileName(inputFile string) string {
+	inputFile = f.outputPath(inputFile)
+	if f.Translate {
+		inputFile = strings.TrimSuffix(inputFile, filepath.Ext(inputFile)) + ".en" + filepath.Ext(inputFile)
+	}
+	return transcriptionFileName(inputFile, f.Format)
+}
+
+// formatTranscription renders a response in the requested format, re-wrapping subtitle cues to maxLineLength when it is set
+func formatTranscription(resp openai.AudioResponse, format openai.AudioResponseFormat, maxLineLength int) ([]byte, error) {
+	switch format {
+	case openai.AudioResponseFormatJSON:
+		return marshalTranscription(struct {
+			Text string `json:"text"`
+		}{Text: resp.Text})
+
+	case openai.AudioResponseFormatVerboseJSON:
+		return marshalTranscription(resp)
+
+	case openai.AudioResponseFormatSRT, openai.AudioResponseFormatVTT:
+		if maxLineLength > 0 {
+			return []byte(wrapSubtitleCues(resp.Text, maxLineLength)), nil
+		}
+		return []byte(strings.TrimRight(resp.Text, "\n") + "\n"), nil
+
+	case openai.AudioResponseFormatText:
+		return []byte(strings.TrimRight(resp.Text, "\n") + "\n"), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported transcription format %s", format)
+	}
+}
+
+func marshalTranscription(v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// wrapSubtitleCues re-wraps the text of each SRT or VTT cue so no line is longer than maxLineLength,
+// keeping cue numbers, timings, and blocks without a timing (such as the WEBVTT header) as they are
+func wrapSubtitleCues(subtitles string, maxLineLength int) string {
+	subtitles = strings.ReplaceAll(subtitles, "\r\n", "\n")
+	blocks := strings.Split(strings.Trim(subtitles, "\n"), "\n\n")
+
+	for i, block := range blocks {
+		lines := strings.Split(block, "\n")
+		timing := -1
+		for j, line := range lines {
+			if strings.Contains(line, "-->") {
+				timing = j
+				break
+			}
+		}
+		if timing < 0 {
+			continue
+		}
+
+		text := strings.Join(lines[timing+1:], " ")
+		wrapped := append(lines[:timing+1:timing+1], wrapWords(text, maxLineLength)...)
+		blocks[i] = strings.Join(wrapped, "\n")
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// wrapWords greedily fills lines of at most maxLineLength characters, giving a longer word a line of its own
+func wrapWords(text string, maxLineLength int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case len([]rune(line))+1+len([]rune(word)) <= maxLineLength:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transcription Output", func() {
+	It("should name the output after the input file", func() {
+		Ω(transcriptionFileName("meetings/standup.m4a", openai.AudioResponseFormatSRT)).To(Equal("meetings/standup.srt"))
+		Ω(transcriptionFileName("standup.mp3", openai.AudioResponseFormatVerboseJSON)).To(Equal("standup.json"))
+		Ω(transcriptionFileName("standup", openai.AudioResponseFormatText)).To(Equal("standup.txt"))
+	})
+
+	It("should mark translations as English", func() {
+		f := NewTranslationFlags()
+		f.Format = openai.AudioResponseFormatVTT
+		Ω(f.outputFileName("voicemail.ogg")).To(Equal("voicemail.en.vtt"))
+
+		f.Translate = false
+		Ω(f.outputFileName("voicemail.ogg")).To(Equal("voicemail.vtt"))
+	})
+
+	It("should have a translate-audio command", func() {
+		translateCmd, _, err := NewRootCmd().Find([]string{"translate-audio"})
+		Ω(err).ToNot(HaveOccurred())
+		Ω(translateCmd.Name()).To(Equal("translate-audio"))
+		Ω(translateCmd.PersistentFlags().Lookup(FlagLanguage)).To(BeNil())
+	})
+
+	It("should render json with only the text", func() {
+		resp := openai.AudioResponse{Text: "Hello there.", Language: "english"}
+		output, err := formatTranscription(resp, openai.AudioResponseFormatJSON, 0)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(string(output)).To(MatchJSON(`{"text": "Hello there."}`))
+
+		output, err = formatTranscription(resp, openai.AudioResponseFormatVerboseJSON, 0)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(string(output)).To(ContainSubstring(`"language": "english"`))
+	})
+
+	It("should re-wrap subtitle cues", func() {
+		srt := "1\r\n00:00:00,000 --> 00:00:04,000\r\nWelcome everyone to the weekly planning meeting\r\n\r\n" +
+			"2\r\n00:00:04,000 --> 00:00:06,000\r\nLet's start.\r\n"
+		Ω(wrapSubtitleCues(srt, 20)).To(Equal("1\n00:00:00,000 --> 00:00:04,000\nWelcome everyone to\nthe weekly planning\nmeeting\n\n" +
+			"2\n00:00:04,000 --> 00:00:06,000\nLet's start.\n"))
+
+		vtt := "WEBVTT\n\n00:00:00.000 --> 00:00:04.000\nWelcome\neveryone\n"
+		Ω(wrapSubtitleCues(vtt, 42)).To(Equal("WEBVTT\n\n00:00:00.000 --> 00:00:04.000\nWelcome everyone\n"))
+	})
+
+	It("should give words longer than the line a line of their own", func() {
+		Ω(wrapWords("a supercalifragilistic word", 10)).To(Equal([]string{"a", "supercalifragilistic", "word"}))
+		Ω(wrapWords("", 10)).To(BeEmpty())
+	})
+})
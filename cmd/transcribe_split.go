@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// audioChunkSize is the size from which a recording is split, and the largest chunk it is split into, leaving
+	// room under the 25 MB the transcription and translation endpoints accept for the rest of the multipart request,
+	// which the limit also counts
+	audioChunkSize = 24 * 1000 * 1000
+)
+
+// AudioChunk is one piece of a recording split to fit under audioChunkSize
+type AudioChunk struct {
+	File string
+	// Start is where the chunk begins in the recording, in seconds, or negative when the splitter can't tell,
+	// in which case the chunk follows on from the duration transcribed for the chunk before
+	Start float64
+}
+
+// AudioSplitter splits a recording into chunks of at most maxSize bytes, written to dir
+type AudioSplitter interface {
+	Split(file string, maxSize int64, dir string) ([]AudioChunk, error)
+}
+
+// audioSplitters holds the splitters for each file extension; --split-command handles any other
+var audioSplitters = map[string]AudioSplitter{
+	".wav": wavSplitter{},
+}
+
+// audioSplitterFor returns the splitter for a file, preferring a built-in one to the split command
+func audioSplitterFor(file string, splitCommand string) (AudioSplitter, error) {
+	if splitter, ok := audioSplitters[strings.ToLower(filepath.Ext(file))]; ok {
+		return splitter, nil
+	}
+	if splitCommand != "" {
+		return commandSplitter{command: splitCommand}, nil
+	}
+	return nil, fmt.Errorf("%s is larger than 24 MB, only WAV files can be split without --%s", file, FlagSplitCommand)
+}
+
+const (
+	// silenceSearch is how far back from the size limit to look for the quietest point to split at
+	silenceSearch = 30.0
+	// silenceWindow is the length of audio over which the level is measured when looking for silence
+	silenceWindow = 0.05
+)
+
+// wavSplitter splits PCM WAV files at the quietest point before each chunk reaches the size limit
+type wavSplitter struct{}
+
+type wavHeader struct {
+	format      []byte
+	audioFormat uint16
+	sampleRate  int64
+	blockAlign  int64
+	bits        int
+	dataOffset  int64
+	dataSize    int64
+}
+
+func (wavSplitter) Split(file string, maxSize int64, dir string) ([]AudioChunk, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = in.Close() }()
+
+	header, err := readWAVHeader(in)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	bytesPerSecond := header.sampleRate * header.blockAlign
+	maxData := (maxSize - int64(wavHeaderSize(header.format))) / header.blockAlign * header.blockAlign
+	if maxData < bytesPerSecond {
+		return nil, fmt.Errorf("%s: chunks of %d bytes would be shorter than a second", file, maxSize)
+	}
+	search := min(int64(silenceSearch*float64(bytesPerSecond)), maxData/4) / header.blockAlign * header.blockAlign
+
+	var chunks []AudioChunk
+	for start := int64(0); start < header.dataSize; {
+		end := header.dataSize
+		if end-start > maxData {
+			end, err = quietestWAVPoint(in, header, start+maxData-search, start+maxData)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+		}
+
+		chunk := AudioChunk{
+			File:  filepath.Join(dir, fmt.Sprintf("chunk-%03d.wav", len(chunks)+1)),
+			Start: float64(start) / float64(bytesPerSecond),
+		}
+		if err := writeWAVChunk(chunk.File, header.format, io.NewSectionReader(in, header.dataOffset+start, end-start)); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+		start = end
+	}
+	return chunks, nil
+}
+
+// readWAVHeader finds the fmt and data chunks of a WAV file without reading its audio
+func readWAVHeader(r io.ReaderAt) (wavHeader, error) {
+	var header wavHeader
+	riff := make([]byte, 12)
+	if _, err := r.ReadAt(riff, 0); err != nil || string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return header, fmt.Errorf("not a WAV file")
+	}
+
+	chunkHeader := make([]byte, 8)
+	for offset := int64(12); ; {
+		if _, err := r.ReadAt(chunkHeader, offset); err != nil {
+			return header, fmt.Errorf("WAV file has no data")
+		}
+		size := int64(binary.LittleEndian.Uint32(chunkHeader[4:8]))
+		switch string(chunkHeader[0:4]) {
+		case "fmt ":
+			if size < 16 {
+				return header, fmt.Errorf("WAV format is too short")
+			}
+			header.format = make([]byte, size)
+			if _, err := r.ReadAt(header.format, offset+8); err != nil {
+				return header, fmt.Errorf("WAV format is truncated")
+			}
+			header.audioFormat = binary.LittleEndian.Uint16(header.format[0:2])
+			header.sampleRate = int64(binary.LittleEndian.Uint32(header.format[4:8]))
+			header.blockAlign = int64(binary.LittleEndian.Uint16(header.format[12:14]))
+			header.bits = int(binary.LittleEndian.Uint16(header.format[14:16]))
+			if header.sampleRate == 0 || header.blockAlign == 0 {
+				return header, fmt.Errorf("WAV format has no sample rate")
+			}
+
+		case "data":
+			if header.format == nil {
+				return header, fmt.Errorf("WAV data before format")
+			}
+			header.dataOffset = offset + 8
+			header.dataSize = size
+			// streamed WAV files may not know the size of their data, so it runs to the end of the file
+			if sizer, ok := r.(interface{ Stat() (os.FileInfo, error) }); ok {
+				if info, err := sizer.Stat(); err == nil {
+					header.dataSize = min(size, info.Size()-header.dataOffset)
+				}
+			}
+			header.dataSize = header.dataSize / header.blockAlign * header.blockAlign
+			return header, nil
+		}
+		offset += 8 + size + size%2
+	}
+}
+
+// quietestWAVPoint returns the offset into the data, between from and to, in the middle of the quietest window
+func quietestWAVPoint(r io.ReaderAt, header wavHeader, from int64, to int64) (int64, error) {
+	window := max(int64(silenceWindow*float64(header.sampleRate)), 1) * header.blockAlign
+	data := make([]byte, to-from)
+	if _, err := r.ReadAt(data, header.dataOffset+from); err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	best, bestLevel := to, math.Inf(1)
+	for start := int64(0); start+window <= int64(len(data)); start += window {
+		level := wavLevel(header, data[start:start+window])
+		// prefer the later of equally quiet windows, for longer chunks
+		if level <= bestLevel {
+			best, bestLevel = from+start+window/2/header.blockAlign*header.blockAlign, level
+		}
+	}
+	return best, nil
+}
+
+// wavLevel is the mean absolute amplitude of the first channel, scaled to 16 bits
+func wavLevel(header wavHeader, data []byte) float64 {
+	bytesPerSample := header.bits / 8
+	var sum float64
+	frames := 0
+	for frame := 0; frame+bytesPerSample <= len(data); frame += int(header.blockAlign) {
+		sample := data[frame : frame+bytesPerSample]
+		var value float64
+		switch {
+		case header.audioFormat == 3 && bytesPerSample == 4:
+			value = float64(math.Float32frombits(binary.LittleEndian.Uint32(sample))) * 32767
+		case bytesPerSample == 1:
+			value = float64(int(sample[0])-128) * 256
+		case bytesPerSample >= 2:
+			// the two most significant bytes of a little endian sample
+			value = float64(int16(binary.LittleEndian.Uint16(sample[bytesPerSample-2:])))
+		}
+		sum += math.Abs(value)
+		frames++
+	}
+	if frames == 0 {
+		return 0
+	}
+	return sum / float64(frames)
+}
+
+func wavHeaderSize(format []byte) int {
+	return 12 + 8 + len(format) + 8
+}
+
+func writeWAVChunk(fileName string, format []byte, data *io.SectionReader) error {
+	out, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = out.Close() }()
+
+	if _, err := out.Write(wavHeaderBytes(format, int(data.Size()))); err != nil {
+		return err
+	}
+	_, err = io.Copy(out, data)
+	return err
+}
+
+// commandSplitter runs --split-command, such as ffmpeg, to split formats without a built-in splitter.
+// The command gets the recording in $SPLIT_INPUT and writes its chunks to $SPLIT_DIR, which are transcribed in
+// the order of their names.
+type commandSplitter struct {
+	command string
+}
+
+func (s commandSplitter) Split(file string, maxSize int64, dir string) ([]AudioChunk, error) {
+	shell := defaultShell()
+	c := exec.Command(shell, shellCommandArgs(shell, s.command)...)
+	c.Env = append(os.Environ(), "SPLIT_INPUT="+file, "SPLIT_DIR="+dir)
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("split command failed: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var chunks []AudioChunk
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() > maxSize {
+			return nil, fmt.Errorf("split command wrote %s, which is larger than %d bytes", entry.Name(), maxSize)
+		}
+		chunks = append(chunks, AudioChunk{File: filepath.Join(dir, entry.Name()), Start: -1})
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("split command wrote no chunks to $SPLIT_DIR")
+	}
+	return chunks, nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// toneWAV returns a 16-bit mono WAV of a tone, with the seconds given in silences left quiet
+func toneWAV(sampleRate int, seconds int, silences ...int) []byte {
+	format := binary.LittleEndian.AppendUint16(nil, 1)
+	format = binary.LittleEndian.AppendUint16(format, 1)
+	format = binary.LittleEndian.AppendUint32(format, uint32(sampleRate))
+	format = binary.LittleEndian.AppendUint32(format, uint32(sampleRate*2))
+	format = binary.LittleEndian.AppendUint16(format, 2)
+	format = binary.LittleEndian.AppendUint16(format, 16)
+
+	var data []byte
+	for i := range sampleRate * seconds {
+		sample := int16(8000 * math.Sin(float64(i)*2*math.Pi*440/float64(sampleRate)))
+		for _, silence := range silences {
+			if i/sampleRate == silence {
+				sample = 0
+			}
+		}
+		data = binary.LittleEndian.AppendUint16(data, uint16(sample))
+	}
+	return append(wavHeaderBytes(format, len(data)), data...)
+}
+
+var _ = Describe("Transcription Splitting", func() {
+	It("should split WAV files at silence", func() {
+		dir := GinkgoT().TempDir()
+		file := filepath.Join(dir, "meeting.wav")
+		// 1000 samples a second, 2000 bytes, so chunks of 8 seconds fit in 16100 bytes
+		Ω(os.WriteFile(file, toneWAV(1000, 20, 6, 13), 0644)).To(Succeed())
+
+		chunks, err := wavSplitter{}.Split(file, 16100, dir)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(chunks).To(HaveLen(3))
+		Ω(chunks[0].Start).To(Equal(0.0))
+		Ω(chunks[1].Start).To(BeNumerically(">=", 6))
+		Ω(chunks[1].Start).To(BeNumerically("<", 7))
+		Ω(chunks[2].Start).To(BeNumerically(">=", 13))
+		Ω(chunks[2].Start).To(BeNumerically("<", 14))
+
+		total := 0
+		for _, chunk := range chunks {
+			data, err := os.ReadFile(chunk.File)
+			Ω(err).ToNot(HaveOccurred())
+			Ω(len(data)).To(BeNumerically("<=", 16100))
+			_, audio, err := readWAV(data)
+			Ω(err).ToNot(HaveOccurred())
+			total += len(audio)
+		}
+		Ω(total).To(Equal(40000))
+	})
+
+	It("should reject files that are not WAV", func() {
+		dir := GinkgoT().TempDir()
+		file := filepath.Join(dir, "meeting.wav")
+		Ω(os.WriteFile(file, []byte("ID3 not a wav file"), 0644)).To(Succeed())
+		_, err := wavSplitter{}.Split(file, 16100, dir)
+		Ω(err).To(MatchError(ContainSubstring("not a WAV file")))
+	})
+
+	It("should only split other formats with a split command", func() {
+		_, err := audioSplitterFor("meeting.m4a", "")
+		Ω(err).To(MatchError(ContainSubstring("--split-command")))
+
+		splitter, err := audioSplitterFor("meeting.WAV", "ffmpeg")
+		Ω(err).ToNot(HaveOccurred())
+		Ω(splitter).To(Equal(wavSplitter{}))
+	})
+
+	It("should take chunks from the split command in name order", func() {
+		dir := GinkgoT().TempDir()
+		file := filepath.Join(dir, "meeting.m4a")
+		Ω(os.WriteFile(file, []byte("audio"), 0644)).To(Succeed())
+		chunksDir := filepath.Join(dir, "chunks")
+		Ω(os.Mkdir(chunksDir, 0755)).To(Succeed())
+
+		splitter := commandSplitter{command: `cp "$SPLIT_INPUT" "$SPLIT_DIR/b.m4a" && cp "$SPLIT_INPUT" "$SPLIT_DIR/a.m4a"`}
+		chunks, err := splitter.Split(file, 100, chunksDir)
+		Ω(err).ToNot(HaveOccurred())
+		Ω(chunks).To(Equal([]AudioChunk{
+			{File: filepath.Join(chunksDir, "a.m4a"), Start: -1},
+			{File: filepath.Join(chunksDir, "b.m4a"), Start: -1},
+		}))
+	})
+
+	It("should reject chunks from the split command that are too large", func() {
+		dir := GinkgoT().TempDir()
+		file := filepath.Join(dir, "meeting.m4a")
+		Ω(os.WriteFile(file, []byte("audio"), 0644)).To(Succeed())
+		chunksDir := filepath.Join(dir, "chunks")
+		Ω(os.Mkdir(chunksDir, 0755)).To(Succeed())
+
+		splitter := commandSplitter{command: `cp "$SPLIT_INPUT" "$SPLIT_DIR/a.m4a"`}
+		_, err := splitter.Split(file, 4, chunksDir)
+		Ω(err).To(MatchError("split command wrote a.m4a, which is larger than 4 bytes"))
+	})
+
+	It("should divide chunks into runs of neighbours", func() {
+		Ω(chunkRuns(7, 3)).To(Equal([][2]int{{0, 3}, {3, 5}, {5, 7}}))
+		Ω(chunkRuns(2, 3)).To(Equal([][2]int{{0, 1}, {1, 2}}))
+	})
+
+	It("should prompt with the tail of the previous chunk", func() {
+		Ω(chunkPrompt("", "")).To(Equal(""))
+		Ω(chunkPrompt("Acme standup.", "We shipped it.")).To(Equal("Acme standup. We shipped it."))
+
+		prompt := chunkPrompt("", "start "+strings.Repeat("word ", 60))
+		Ω(len(prompt)).To(BeNumerically("<=", maxPromptTail))
+		Ω(prompt).To(HavePrefix("word"))
+	})
+
+	It("should stitch timestamps and render subtitles", func() {
+		var first, second openai.AudioResponse
+		Ω(json.Unmarshal([]byte(`{"language": "english", "duration": 6.5, "text": " Hello everyone.",
+			"segments": [{"id": 0, "start": 0.0, "end": 2.0, "text": " Hello everyone."}],
+			"words": [{"word": "Hello", "start": 0.0, "end": 0.5}]}`), &first)).To(Succeed())
+		Ω(json.Unmarshal([]byte(`{"duration": 4.0, "text": "Let's start.",
+			"segments": [{"id": 0, "start": 1.0, "end": 2.25, "text": "Let's start."}]}`), &second)).To(Succeed())
+
+		resp := stitchTranscriptions([]AudioChunk{{Start: 0}, {Start: -1}}, []openai.AudioResponse{first, second})
+		Ω(resp.Text).To(Equal("Hello everyone. Let's start."))
+		Ω(resp.Language).To(Equal("english"))
+		Ω(resp.Duration).To(Equal(10.5))
+		Ω(resp.Segments).To(HaveLen(2))
+		Ω(resp.Segments[1].ID).To(Equal(1))
+		Ω(resp.Segments[1].Start).To(Equal(7.5))
+		Ω(resp.Words[0].End).To(Equal(0.5))
+
+		Ω(renderSubtitles(resp, openai.AudioResponseFormatSRT)).To(Equal(
+			"1\n00:00:00,000 --> 00:00:02,000\nHello everyone.\n\n2\n00:00:07,500 --> 00:00:08,750\nLet's start.\n\n"))
+		Ω(renderSubtitles(resp, openai.AudioResponseFormatVTT)).To(HavePrefix("WEBVTT\n\n00:00:00.000 --> 00:00:02.000\n"))
+		Ω(subtitleTimestamp(3725.5, ",")).To(Equal("01:02:05,500"))
+	})
+})
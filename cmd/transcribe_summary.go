@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	meetingNotesInstructions = "You write meeting notes from a transcript. Reply in Markdown with these sections: " +
+		"## Summary, a short paragraph on what was discussed. " +
+		"## Decisions, a list of the decisions made. " +
+		"## Action Items, a list of tasks, each with its owner, and due date when one was given, using Unassigned when no owner was named. " +
+		"## Open Questions, a list of questions left unresolved. " +
+		"Only include what is in the transcript, naming people as they are named in it, and write None for an empty section."
+	standupNotesInstructions = "You write notes from the transcript of a daily standup. Reply in Markdown with these sections: " +
+		"## Summary, a sentence or two on the state of the team's work. " +
+		"## Updates, for each person, what they finished, what they are doing next, and anything blocking them. " +
+		"## Decisions, a list of the decisions made. " +
+		"## Action Items, a list of tasks, each with its owner, using Unassigned when no owner was named. " +
+		"Only include what is in the transcript, naming people as they are named in it, and write None for an empty section."
+	callNotesInstructions = "You write notes from the transcript of a call with a customer. Reply in Markdown with these sections: " +
+		"## Summary, a short paragraph on the purpose and outcome of the call. " +
+		"## Customer Needs, a list of the problems, requests, and questions the customer raised. " +
+		"## Decisions, a list of what was agreed. " +
+		"## Action Items, a list of follow ups, each with its owner, and due date when one was given, using Unassigned when no owner was named. " +
+		"Only include what is in the transcript, naming people as they are named in it, and write None for an empty section."
+)
+
+// summaryTemplates are the built-in notes templates for --summary-template; any other value is a file of instructions
+var summaryTemplates = map[string]string{
+	"meeting": meetingNotesInstructions,
+	"standup": standupNotesInstructions,
+	"call":    callNotesInstructions,
+}
+
+// summaryTemplateNames lists the built-in templates, for help and errors
+func summaryTemplateNames() string {
+	var names []string
+	for name := range summaryTemplates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// summaryInstructions returns the instructions of a built-in template, or the contents of a template file
+func summaryInstructions(template string) (string, error) {
+	if instructions, ok := summaryTemplates[template]; ok {
+		return instructions, nil
+	}
+	instructions, err := os.ReadFile(template)
+	if err != nil {
+		return "", fmt.Errorf("summary-template must be one of %s, or a file: %w", summaryTemplateNames(), err)
+	}
+	return string(instructions), nil
+}
+
+// sessionFileName names the session of the notes for an input file, such as standup.session.json for standup.m4a,
+// in the --output-dir when there is one
+func (f *TranscriptionFlags) sessionFileName(inputFile string) string {
+	inputFile = f.outputPath(inputFile)
+	return strings.TrimSuffix(inputFile, filepath.Ext(inputFile)) + ".session.json"
+}
+
+// transcriptText returns the words of a transcript, without the cue numbers and timings of subtitles
+func transcriptText(resp openai.AudioResponse, format openai.AudioResponseFormat) string {
+	if format != openai.AudioResponseFormatSRT && format != openai.AudioResponseFormatVTT {
+		return strings.TrimSpace(resp.Text)
+	}
+
+	var texts []string
+	for _, block := range strings.Split(strings.ReplaceAll(resp.Text, "\r\n", "\n"), "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				texts = append(texts, strings.Join(lines[i+1:], " "))
+				break
+			}
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// summarizeTranscript sends the transcript of a file to ChatGPT with the template, printing the notes unless the
+// response is suppressed, and writes both to a session file, so follow-up questions can be asked with
+// chat --session-file. Returns the session file.
+func summarizeTranscript(f *TranscriptionFlags, chatContext *ChatContext, client *openai.Client, file string, transcript string) (string, error) {
+	instructions, err := summaryInstructions(f.SummaryTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	chatFlags := ChatFlagsFromTranscriptionFlags(f, f.sessionFileName(file))
+	chatCompletionRequest := &openai.ChatCompletionRequest{
+		Model: chatFlags.model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: instructions},
+		},
+		Temperature:         chatFlags.temperature,
+		MaxCompletionTokens: chatFlags.maxCompletionTokens,
+		TopP:                chatFlags.topP,
+	}
+	message := fmt.Sprintf("Transcript of %s:\n\n%s", filepath.Base(file), transcript)
+
+	if chatContext.SuppressResponse {
+		// files transcribed at the same time would garble each other's spinners, so the notes are not streamed
+		chatCompletionRequest.Messages = append(chatCompletionRequest.Messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleUser,
+			Content: message,
+		})
+		resp, err := client.CreateChatCompletion(context.Background(), apiChatCompletionRequest(chatCompletionRequest))
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			return "", fmt.Errorf("no notes returned for %s", file)
+		}
+		chatCompletionRequest.Messages = append(chatCompletionRequest.Messages, resp.Choices[0].Message)
+	} else if err := sendChatMessages(chatFlags, chatContext, chatCompletionRequest, client, message); err != nil {
+		return "", err
+	}
+
+	if err := writeSessionFile(chatFlags, chatCompletionRequest); err != nil {
+		return "", err
+	}
+	return chatFlags.sessionFile, nil
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transcription Summary", func() {
+	It("should ask for summary, decisions, and action items with owners", func() {
+		instructions, err := summaryInstructions("meeting")
+		Ω(err).ToNot(HaveOccurred())
+		Ω(instructions).To(ContainSubstring("## Summary"))
+		Ω(instructions).To(ContainSubstring("## Decisions"))
+		Ω(instructions).To(ContainSubstring("## Action Items"))
+		Ω(instructions).To(ContainSubstring("owner"))
+	})
+
+	It("should read templates from a file", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "retro.txt")
+		Ω(os.WriteFile(file, []byte("List what went well."), 0644)).To(Succeed())
+		Ω(summaryInstructions(file)).To(Equal("List what went well."))
+
+		_, err := summaryInstructions("retro")
+		Ω(err).To(MatchError(ContainSubstring("call, meeting, standup")))
+	})
+
+	It("should name the session after the input file", func() {
+		f := NewTranscriptionFlags()
+		Ω(f.sessionFileName("recordings/standup.m4a")).To(Equal("recordings/standup.session.json"))
+
+		f.OutputDir = "notes"
+		Ω(f.sessionFileName("recordings/standup.m4a")).To(Equal(filepath.Join("notes", "standup.session.json")))
+	})
+
+	It("should summarize the words of subtitles", func() {
+		resp := openai.AudioResponse{Text: "WEBVTT\n\n00:00:00.000 --> 00:00:02.000\nHello\neveryone.\n\n00:00:02.000 --> 00:00:03.000\nLet's start.\n"}
+		Ω(transcriptText(resp, openai.AudioResponseFormatVTT)).To(Equal("Hello everyone. Let's start."))
+
+		resp = openai.AudioResponse{Text: "1\r\n00:00:00,000 --> 00:00:02,000\r\nHello everyone.\r\n"}
+		Ω(transcriptText(resp, openai.AudioResponseFormatSRT)).To(Equal("Hello everyone."))
+
+		resp = openai.AudioResponse{Text: " Hello everyone.\n"}
+		Ω(transcriptText(resp, openai.AudioResponseFormatText)).To(Equal("Hello everyone."))
+	})
+})
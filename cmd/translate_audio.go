@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewTranslateAudioCmd(rootFlags *RootFlags) *cobra.Command {
+	translationFlags := NewTranslationFlags()
+	chatContext := NewChatContext()
+	var cmd = &cobra.Command{
+		Use:   "translate-audio",
+		Short: "Translate audio in any language to English text",
+		Long:  "Translate audio in any language to English text",
+		RunE:  transcribeCmdRunner(rootFlags, translationFlags, chatContext),
+	}
+	setChatContext(cmd, chatContext)
+
+	AddTranslationModelFlag(&translationFlags.Model, cmd.PersistentFlags())
+	AddTranscriptionFormatFlag(&translationFlags.FormatStr, cmd.PersistentFlags())
+	AddMaxLineLengthFlag(&translationFlags.MaxLineLength, cmd.PersistentFlags())
+	AddConcurrencyFlag(&translationFlags.Concurrency, cmd.PersistentFlags())
+	AddSplitCommandFlag(&translationFlags.SplitCommand, cmd.PersistentFlags())
+	AddInputFileFlag(&translationFlags.inputFiles, cmd.PersistentFlags())
+	AddInputDirFlag(&translationFlags.InputDir, cmd.PersistentFlags())
+	AddGlobFlag(&translationFlags.Glob, cmd.PersistentFlags())
+	AddOutputDirFlag(&translationFlags.OutputDir, "", "Directory to write the translations to, instead of next to each recording", cmd.PersistentFlags())
+	AddTranscriptionJSONFlag(&translationFlags.JSON, cmd.PersistentFlags())
+	AddInitialSystemMessageFlag(&translationFlags.initialSystemMessage, cmd.PersistentFlags())
+	_ = cmd.MarkPersistentFlagRequired(FlagApiKey)
+	cmd.MarkFlagsOneRequired(FlagInputFile, FlagInputDir)
+
+	return cmd
+}
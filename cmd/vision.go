@@ -1,11 +1,7 @@
 package cmd
 
 import (
-	"bufio"
-	"context"
-	"encoding/base64"
 	"fmt"
-	"os"
 
 	"github.com/sashabaranov/go-openai"
 	log "github.com/sirupsen/logrus"
@@ -25,11 +21,16 @@ func NewVisionCmd(rootFlags *RootFlags) *cobra.Command {
 
 	AddModelFlag(&visionFlags.model, cmd.PersistentFlags())
 	AddDetailFlag(&visionFlags.DetailStr, cmd.PersistentFlags())
+	AddPagesFlag(&visionFlags.pages, cmd.PersistentFlags())
 	AddRoleFlag(&visionFlags.role, cmd.PersistentFlags())
 	AddInputFileFlag(&visionFlags.inputFiles, cmd.PersistentFlags())
 	AddSessionFileFlag(&visionFlags.sessionFile, cmd.PersistentFlags())
 	AddSkipWriteSessionFileFlag(&visionFlags.skipWriteSessionFile, cmd.PersistentFlags())
 	AddInitialSystemMessageFlag(&visionFlags.initialSystemMessage, cmd.PersistentFlags())
+	AddPersonaFlag(&visionFlags.persona, cmd.PersistentFlags())
+	AddPersonasFileFlag(&visionFlags.personasFile, cmd.PersistentFlags())
+	AddRenderFlag(&visionFlags.render, cmd.PersistentFlags())
+	AddNoRenderFlag(&visionFlags.noRender, cmd.PersistentFlags())
 	_ = cmd.MarkPersistentFlagRequired(FlagApiKey)
 	_ = cmd.MarkPersistentFlagRequired(FlagInputFile)
 
@@ -37,14 +38,24 @@ func NewVisionCmd(rootFlags *RootFlags) *cobra.Command {
 }
 
 func visionCmdRunner(rootFlags *RootFlags, visionFlags *VisionFlags, chatContext *ChatContext) func(cmd *cobra.Command, args []string) error {
-	return func(_ *cobra.Command, _ []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
 		log.Debugf("visionCmd called")
 		err := visionFlags.ValidateFlags()
 		if err != nil {
 			log.WithError(err).Fatal()
 		}
 
+		chatFlags := ChatFlagsFromVisionFlags(visionFlags)
+		if err := chatFlags.ValidateFlags(); err != nil {
+			log.WithError(err).Fatal()
+		}
+		if err := applyPersona(cmd, chatFlags); err != nil {
+			log.WithError(err).Fatal()
+		}
+		visionFlags.model = chatFlags.model
+
 		chatContext.InteractiveSession = detectTerminal()
+		chatContext.RenderMarkdown = detectRenderMarkdown(visionFlags.render, visionFlags.noRender, chatContext)
 		if chatContext.InteractiveSession {
 			printVisionBanner(visionFlags)
 		}
@@ -53,89 +64,27 @@ func visionCmdRunner(rootFlags *RootFlags, visionFlags *VisionFlags, chatContext
 			log.WithError(err).Fatal()
 		}
 
-		chatFlags := ChatFlagsFromVisionFlags(visionFlags)
 		chatCompletionRequest := loadOrCreateChatCompletionRequest(chatFlags, chatContext)
-		if visionFlags.initialSystemMessage != "" {
+		recordPersona(chatFlags, chatCompletionRequest)
+		if chatFlags.initialSystemMessage != "" {
 			chatCompletionRequest.Messages = append(chatCompletionRequest.Messages, openai.ChatCompletionMessage{
 				Role:    "system",
-				Content: visionFlags.initialSystemMessage,
+				Content: chatFlags.initialSystemMessage,
 			})
 		}
 
-		reader := bufio.NewReader(os.Stdin)
-		chatRequestString := readUserInput(chatContext, reader, "Enter Message")
-		if len(chatRequestString) == 0 {
-			ErrorFmt.Printf("No Message to Send, exiting...\n")
-			return nil
-		}
-
-		if err := sendVisionMessages(visionFlags, chatContext, chatCompletionRequest, client, chatRequestString); err != nil {
-			log.WithError(err).Fatal()
-		}
-
-		if shouldWriteSession(chatFlags) {
-			writeSessionFile(chatFlags, chatCompletionRequest)
-		}
-
+		runChatLoop(chatFlags, chatContext, chatCompletionRequest, client)
 		return nil
 	}
 }
 
 func printVisionBanner(f *VisionFlags) {
 	TitleFmt.Printf("ChatGPT CLI v%s\n", version)
+	if f.persona != "" {
+		fmt.Printf("Persona: %s\n", f.persona)
+	}
 	fmt.Printf("Model: %s, detail: %s\n", f.model, f.Detail)
 	fmt.Printf("- Press TAB after entering a message to send.\n")
 	fmt.Printf("- Press TAB or CTRL+C with a blank message to terminate the session without sending.\n")
-}
-
-// sendVisionMessages sends messages to ChatGPT and prints the response
-func sendVisionMessages(f *VisionFlags, chatContext *ChatContext, chatCompletionRequest *openai.ChatCompletionRequest, client *openai.Client, chatRequestString string) error {
-	mySpinner := newSpinner()
-	successSpinner, _ := mySpinner.Start("Sending to ChatGPT, please wait...")
-
-	content := []openai.ChatMessagePart{
-		{
-			Type: openai.ChatMessagePartTypeText,
-			Text: chatRequestString,
-		},
-	}
-
-	for _, file := range f.inputFiles {
-		image, err := os.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("failed to open file %s: %w", file, err)
-		}
-
-		// Encode the image to base64
-		encodedImage := base64.StdEncoding.EncodeToString(image)
-
-		content = append(content, openai.ChatMessagePart{
-			Type: openai.ChatMessagePartTypeImageURL,
-			ImageURL: &openai.ChatMessageImageURL{
-				URL:    "data:image/jpeg;base64," + encodedImage,
-				Detail: f.Detail,
-			},
-		})
-	}
-
-	chatCompletionRequest.Messages = append(chatCompletionRequest.Messages, openai.ChatCompletionMessage{
-		Role:         f.role,
-		MultiContent: content,
-	})
-	resp, err := client.CreateChatCompletion(context.Background(), *chatCompletionRequest)
-	if err != nil {
-		successSpinner.Fail(err.Error())
-		return err
-	}
-	successSpinner.Success()
-
-	for _, choice := range resp.Choices {
-		if chatContext.InteractiveSession {
-			AiFmt.Printf("\nChatGPT response:\n")
-		}
-		fmt.Printf("%s\n", choice.Message.Content)
-	}
-	chatCompletionRequest.Messages = append(chatCompletionRequest.Messages, resp.Choices[0].Message)
-
-	return nil
+	fmt.Printf("- The images are sent with the first message, enter /attach <path> to attach another image or PDF to the next message.\n")
 }
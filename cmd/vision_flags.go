@@ -13,6 +13,11 @@ type VisionFlags struct {
 	role                 string
 	initialSystemMessage string
 	inputFiles           []string
+	pages                string
+	persona              string
+	personasFile         string
+	render               bool
+	noRender             bool
 
 	skipWriteSessionFile bool
 	sessionFile          string
@@ -23,12 +28,23 @@ func NewVisionFlags() *VisionFlags {
 }
 
 func (f *VisionFlags) ValidateFlags() error {
-	switch f.DetailStr {
+	detail, err := parseImageDetail(f.DetailStr)
+	if err != nil {
+		return err
+	}
+	f.Detail = detail
+	if _, err := parsePageRange(f.pages); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseImageDetail(detail string) (openai.ImageURLDetail, error) {
+	switch detail {
 	case string(openai.ImageURLDetailAuto), string(openai.ImageURLDetailHigh), string(openai.ImageURLDetailLow):
 		// these are fine, convert to ImageURLDetail
-		f.Detail = openai.ImageURLDetail(f.DetailStr)
+		return openai.ImageURLDetail(detail), nil
 	default:
-		return fmt.Errorf("detail must be one of 'auto', 'high', or 'low'")
+		return "", fmt.Errorf("detail must be one of 'auto', 'high', or 'low'")
 	}
-	return nil
 }
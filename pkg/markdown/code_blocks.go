@@ -0,0 +1,165 @@
+package markdown
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+var (
+	fenceInfoRegex    = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([^`]*)$")
+	fileAttrRegex     = regexp.MustCompile(`(?:title|file|filename|name)=["']?([^"'\s]+)["']?`)
+	fileNamePattern   = `([\w./-]*\w\.[A-Za-z][A-Za-z0-9]*)`
+	precedingHintRegs = []*regexp.Regexp{
+		regexp.MustCompile("`" + fileNamePattern + "`"),
+		regexp.MustCompile(`\*\*` + fileNamePattern + `\*\*`),
+		regexp.MustCompile(`(?:^|\s)` + fileNamePattern + `:\s*$`),
+	}
+	fileNameRegex  = regexp.MustCompile(`^` + fileNamePattern + `$`)
+	extensionRegex = regexp.MustCompile(`^\*(\.[A-Za-z0-9]+)$`)
+)
+
+// CodeBlock is a fenced code block found in Markdown text
+type CodeBlock struct {
+	// Language from the fence info string, may be empty
+	Language string
+	// Filename suggested by the fence info string or the text preceding the block, may be empty
+	Filename string
+	Code     string
+}
+
+// ExtractCodeBlocks finds the fenced code blocks in Markdown text
+func ExtractCodeBlocks(text string) []CodeBlock {
+	var blocks []CodeBlock
+	var current *CodeBlock
+	var fence string
+	var codeLines []string
+	previousLine := ""
+
+	for _, line := range strings.Split(text, "\n") {
+		if current != nil {
+			if isClosingFence(line, fence) {
+				current.Code = strings.Join(codeLines, "\n") + "\n"
+				blocks = append(blocks, *current)
+				current = nil
+				continue
+			}
+			codeLines = append(codeLines, line)
+			continue
+		}
+
+		if m := fenceInfoRegex.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			codeLines = nil
+			current = &CodeBlock{
+				Language: fenceLanguage(m[2]),
+				Filename: fenceFilename(m[2]),
+			}
+			if current.Filename == "" {
+				current.Filename = precedingFilename(previousLine)
+			}
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			previousLine = line
+		}
+	}
+
+	// an unterminated block runs to the end of the text
+	if current != nil && len(codeLines) > 0 {
+		current.Code = strings.Join(codeLines, "\n") + "\n"
+		blocks = append(blocks, *current)
+	}
+	return blocks
+}
+
+// Extension returns the file extension, including the dot, typically used for the block's language
+func (b CodeBlock) Extension() string {
+	if b.Filename != "" && filepath.Ext(b.Filename) != "" {
+		return filepath.Ext(b.Filename)
+	}
+	if b.Language != "" {
+		if lexer := lexers.Get(b.Language); lexer != nil {
+			for _, pattern := range lexer.Config().Filenames {
+				if m := extensionRegex.FindStringSubmatch(pattern); m != nil {
+					return m[1]
+				}
+			}
+		}
+	}
+	return ".txt"
+}
+
+func isClosingFence(line string, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == ""
+}
+
+// fenceLanguage returns the language from a fence info string, such as "go", "python:app.py", or "js {title=app.js}"
+func fenceLanguage(info string) string {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return ""
+	}
+	language, _, _ := strings.Cut(fields[0], ":")
+	language, _, _ = strings.Cut(language, "{")
+	if strings.Contains(language, "=") || (looksLikeFilename(language) && !isLanguageName(language)) {
+		return ""
+	}
+	return language
+}
+
+// fenceFilename returns a filename given in a fence info string, such as "python app.py", "python:app.py",
+// "python title=app.py", or just "app.py"
+func fenceFilename(info string) string {
+	if m := fileAttrRegex.FindStringSubmatch(info); m != nil {
+		return m[1]
+	}
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return ""
+	}
+	if _, file, found := strings.Cut(fields[0], ":"); found && looksLikeFilename(file) {
+		return file
+	}
+	for _, field := range fields {
+		if looksLikeFilename(field) && !isLanguageName(field) {
+			return field
+		}
+	}
+	return ""
+}
+
+// precedingFilename looks for a filename in the line of text before a code block, such as "Save this as `run.sh`:"
+func precedingFilename(line string) string {
+	for _, re := range precedingHintRegs {
+		if m := re.FindStringSubmatch(line); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+func looksLikeFilename(s string) bool {
+	return fileNameRegex.MatchString(s)
+}
+
+// isLanguageName reports if s is the name or alias of a language, lexers.Get also matches on filenames
+func isLanguageName(s string) bool {
+	lexer := lexers.Get(s)
+	if lexer == nil {
+		return false
+	}
+	config := lexer.Config()
+	if strings.EqualFold(config.Name, s) {
+		return true
+	}
+	for _, alias := range config.Aliases {
+		if strings.EqualFold(alias, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package markdown_test
+
+import (
+	"github.com/duanemay/chatgpt-cli/pkg/markdown"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExtractCodeBlocks", func() {
+	It("should find fenced code blocks with their language", func() {
+		blocks := markdown.ExtractCodeBlocks("Some text\n```go\npackage main\n```\nmore\n~~~\nplain\n~~~\n")
+		Ω(blocks).To(HaveLen(2))
+		Ω(blocks[0].Language).To(Equal("go"))
+		Ω(blocks[0].Code).To(Equal("package main\n"))
+		Ω(blocks[0].Extension()).To(Equal(".go"))
+		Ω(blocks[1].Language).To(BeEmpty())
+		Ω(blocks[1].Code).To(Equal("plain\n"))
+		Ω(blocks[1].Extension()).To(Equal(".txt"))
+	})
+
+	It("should take a filename from the fence info string", func() {
+		Ω(markdown.ExtractCodeBlocks("```python app.py\nprint(1)\n```\n")[0].Filename).To(Equal("app.py"))
+		Ω(markdown.ExtractCodeBlocks("```python:src/app.py\nprint(1)\n```\n")[0].Filename).To(Equal("src/app.py"))
+		Ω(markdown.ExtractCodeBlocks("```python title=\"app.py\"\nprint(1)\n```\n")[0].Filename).To(Equal("app.py"))
+
+		block := markdown.ExtractCodeBlocks("```run.sh\necho hi\n```\n")[0]
+		Ω(block.Filename).To(Equal("run.sh"))
+		Ω(block.Language).To(BeEmpty())
+		Ω(block.Extension()).To(Equal(".sh"))
+	})
+
+	It("should take a filename from the preceding text", func() {
+		block := markdown.ExtractCodeBlocks("Save this as `deploy.yaml`:\n\n```yaml\nkey: value\n```\n")[0]
+		Ω(block.Filename).To(Equal("deploy.yaml"))
+		Ω(block.Language).To(Equal("yaml"))
+
+		block = markdown.ExtractCodeBlocks("**main.go**\n```go\npackage main\n```\n")[0]
+		Ω(block.Filename).To(Equal("main.go"))
+
+		block = markdown.ExtractCodeBlocks("Create a file named setup.cfg:\n```ini\n[metadata]\n```\n")[0]
+		Ω(block.Filename).To(Equal("setup.cfg"))
+
+		block = markdown.ExtractCodeBlocks("This needs Python 3.11\n```python\nprint(1)\n```\n")[0]
+		Ω(block.Filename).To(BeEmpty())
+	})
+
+	It("should use the language extension without a filename", func() {
+		block := markdown.ExtractCodeBlocks("Here is a script.\n```bash\necho hi\n```\n")[0]
+		Ω(block.Filename).To(BeEmpty())
+		Ω(block.Extension()).To(Equal(".sh"))
+	})
+
+	It("should include an unterminated block", func() {
+		blocks := markdown.ExtractCodeBlocks("```js\nconsole.log(1)")
+		Ω(blocks).To(HaveLen(1))
+		Ω(blocks[0].Code).To(Equal("console.log(1)\n"))
+	})
+})
@@ -0,0 +1,256 @@
+package markdown
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/pterm/pterm"
+)
+
+var (
+	heading1Fmt   = pterm.NewStyle(pterm.FgLightCyan, pterm.Bold, pterm.Underscore)
+	headingFmt    = pterm.NewStyle(pterm.FgLightCyan, pterm.Bold)
+	boldFmt       = pterm.NewStyle(pterm.Bold)
+	italicFmt     = pterm.NewStyle(pterm.Italic)
+	inlineCodeFmt = pterm.NewStyle(pterm.FgLightYellow)
+	linkFmt       = pterm.NewStyle(pterm.FgLightBlue, pterm.Underscore)
+	dimFmt        = pterm.NewStyle(pterm.FgGray)
+)
+
+var (
+	headingRegex    = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	ruleRegex       = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	quoteRegex      = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	bulletRegex     = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	numberedRegex   = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+(.*)$`)
+	tableSepRegex   = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	inlineCodeRegex = regexp.MustCompile("`([^`]+)`")
+	boldRegex       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicRegex     = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	linkRegex       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// codeStyleName is the chroma style used to highlight fenced code blocks
+const codeStyleName = "monokai"
+
+// Renderer is an io.Writer that renders Markdown for display in a terminal.
+// Text is rendered a line at a time as it is written, so it can be used with streaming output.
+// Tables, and fenced code blocks without a language, are held until complete.
+// Flush must be called once all text has been written.
+type Renderer struct {
+	out     io.Writer
+	partial string
+
+	inCode    bool
+	fence     string
+	lexer     chroma.Lexer
+	codeLines []string
+
+	tableLines []string
+}
+
+// NewRenderer creates a Renderer writing to out
+func NewRenderer(out io.Writer) *Renderer {
+	return &Renderer{out: out}
+}
+
+// Write renders each complete line in p, keeping any incomplete line for the next Write
+func (r *Renderer) Write(p []byte) (int, error) {
+	text := r.partial + string(p)
+	lines := strings.Split(text, "\n")
+	r.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		if err := r.renderLine(line); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush renders any incomplete line, table, or code block still being held
+func (r *Renderer) Flush() error {
+	if r.partial != "" {
+		line := r.partial
+		r.partial = ""
+		if err := r.renderLine(line); err != nil {
+			return err
+		}
+	}
+	if r.inCode {
+		if err := r.flushCode(); err != nil {
+			return err
+		}
+		r.inCode = false
+	}
+	return r.flushTable()
+}
+
+func (r *Renderer) renderLine(line string) error {
+	if r.inCode {
+		return r.renderCodeLine(line)
+	}
+
+	if isTableLine(line) {
+		r.tableLines = append(r.tableLines, line)
+		return nil
+	}
+	if err := r.flushTable(); err != nil {
+		return err
+	}
+
+	if m := fenceInfoRegex.FindStringSubmatch(line); m != nil {
+		r.inCode = true
+		r.fence = m[1]
+		r.lexer = nil
+		if language := fenceLanguage(m[2]); language != "" {
+			r.lexer = lexers.Get(language)
+		}
+		return r.writeString(dimFmt.Sprint(strings.TrimSpace(line)) + "\n")
+	}
+
+	return r.writeString(renderBlockLine(line) + "\n")
+}
+
+func (r *Renderer) renderCodeLine(line string) error {
+	if isClosingFence(line, r.fence) {
+		if err := r.flushCode(); err != nil {
+			return err
+		}
+		r.inCode = false
+		return r.writeString(dimFmt.Sprint(strings.TrimSpace(line)) + "\n")
+	}
+
+	// a known language is highlighted as each line arrives, otherwise the block is held to detect the language
+	if r.lexer != nil {
+		return r.highlight(r.lexer, line+"\n")
+	}
+	r.codeLines = append(r.codeLines, line)
+	return nil
+}
+
+func (r *Renderer) flushCode() error {
+	if len(r.codeLines) == 0 {
+		return nil
+	}
+	code := strings.Join(r.codeLines, "\n") + "\n"
+	r.codeLines = nil
+
+	lexer := lexers.Analyse(code)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	return r.highlight(lexer, code)
+}
+
+func (r *Renderer) highlight(lexer chroma.Lexer, code string) error {
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return r.writeString(code)
+	}
+	return formatters.TTY256.Format(r.out, styles.Get(codeStyleName), iterator)
+}
+
+func (r *Renderer) flushTable() error {
+	if len(r.tableLines) == 0 {
+		return nil
+	}
+	lines := r.tableLines
+	r.tableLines = nil
+
+	hasHeader := false
+	var data [][]string
+	for i, line := range lines {
+		if tableSepRegex.MatchString(line) {
+			hasHeader = hasHeader || i == 1
+			continue
+		}
+		data = append(data, splitTableRow(line))
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	table, err := pterm.DefaultTable.WithHasHeader(hasHeader).WithBoxed().WithData(data).Srender()
+	if err != nil {
+		return r.writeString(strings.Join(lines, "\n") + "\n")
+	}
+	return r.writeString(table + "\n")
+}
+
+func (r *Renderer) writeString(s string) error {
+	_, err := io.WriteString(r.out, s)
+	return err
+}
+
+func isTableLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "|") && strings.Count(trimmed, "|") >= 2
+}
+
+func splitTableRow(line string) []string {
+	trimmed := strings.TrimSpace(line)
+	trimmed = strings.TrimPrefix(trimmed, "|")
+	trimmed = strings.TrimSuffix(trimmed, "|")
+	cells := strings.Split(trimmed, "|")
+	for i, cell := range cells {
+		cells[i] = renderInline(strings.TrimSpace(cell))
+	}
+	return cells
+}
+
+// renderBlockLine renders a single line of text outside a code block or table
+func renderBlockLine(line string) string {
+	if m := headingRegex.FindStringSubmatch(line); m != nil {
+		if len(m[1]) == 1 {
+			return heading1Fmt.Sprint(m[2])
+		}
+		return headingFmt.Sprint(m[2])
+	}
+	if ruleRegex.MatchString(line) {
+		return dimFmt.Sprint(strings.Repeat("─", 40))
+	}
+	if m := quoteRegex.FindStringSubmatch(line); m != nil {
+		return dimFmt.Sprint("│ ") + italicFmt.Sprint(renderInline(m[1]))
+	}
+	if m := bulletRegex.FindStringSubmatch(line); m != nil {
+		return m[1] + "• " + renderInline(m[2])
+	}
+	if m := numberedRegex.FindStringSubmatch(line); m != nil {
+		return m[1] + m[2] + ". " + renderInline(m[3])
+	}
+	return renderInline(line)
+}
+
+// renderInline renders inline code, bold, italic, and links. Text inside inline code is left as is.
+func renderInline(text string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range inlineCodeRegex.FindAllStringSubmatchIndex(text, -1) {
+		sb.WriteString(renderEmphasis(text[last:loc[0]]))
+		sb.WriteString(inlineCodeFmt.Sprint(text[loc[2]:loc[3]]))
+		last = loc[1]
+	}
+	sb.WriteString(renderEmphasis(text[last:]))
+	return sb.String()
+}
+
+func renderEmphasis(text string) string {
+	text = linkRegex.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkRegex.FindStringSubmatch(s)
+		return linkFmt.Sprint(m[1]) + dimFmt.Sprint(" ("+m[2]+")")
+	})
+	text = boldRegex.ReplaceAllStringFunc(text, func(s string) string {
+		m := boldRegex.FindStringSubmatch(s)
+		return boldFmt.Sprint(m[1] + m[2])
+	})
+	text = italicRegex.ReplaceAllStringFunc(text, func(s string) string {
+		m := italicRegex.FindStringSubmatch(s)
+		return italicFmt.Sprint(m[1])
+	})
+	return text
+}
@@ -0,0 +1,67 @@
+package markdown_test
+
+import (
+	"bytes"
+
+	"github.com/duanemay/chatgpt-cli/pkg/markdown"
+	"github.com/pterm/pterm"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Renderer", func() {
+	var out *bytes.Buffer
+	var renderer *markdown.Renderer
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		renderer = markdown.NewRenderer(out)
+	})
+
+	render := func(chunks ...string) string {
+		for _, chunk := range chunks {
+			_, err := renderer.Write([]byte(chunk))
+			Ω(err).ToNot(HaveOccurred())
+		}
+		Ω(renderer.Flush()).To(Succeed())
+		return pterm.RemoveColorFromString(out.String())
+	}
+
+	It("should render headings, lists, and inline styles without markers", func() {
+		output := render("# Title\n", "- **bold** and *italic* and `code`\n", "1. [link](https://example.com)\n")
+		Ω(output).To(ContainSubstring("Title\n"))
+		Ω(output).ToNot(ContainSubstring("#"))
+		Ω(output).To(ContainSubstring("• bold and italic and code\n"))
+		Ω(output).To(ContainSubstring("1. link (https://example.com)\n"))
+	})
+
+	It("should render lines as they are completed", func() {
+		_, _ = renderer.Write([]byte("first li"))
+		Ω(out.String()).To(BeEmpty())
+		_, _ = renderer.Write([]byte("ne\nsecond"))
+		Ω(pterm.RemoveColorFromString(out.String())).To(Equal("first line\n"))
+		Ω(renderer.Flush()).To(Succeed())
+		Ω(pterm.RemoveColorFromString(out.String())).To(Equal("first line\nsecond\n"))
+	})
+
+	It("should highlight fenced code blocks", func() {
+		output := render("```go\n", "func main() {}\n", "```\n")
+		Ω(output).To(ContainSubstring("```go\n"))
+		Ω(output).To(ContainSubstring("func main() {}\n"))
+		Ω(out.String()).To(ContainSubstring("\x1b["))
+	})
+
+	It("should leave markdown inside code blocks alone", func() {
+		output := render("```\n", "# not a heading\n", "```\n")
+		Ω(output).To(ContainSubstring("# not a heading\n"))
+	})
+
+	It("should render tables", func() {
+		output := render("| Name | Value |\n|------|-------|\n| a | 1 |\n", "after\n")
+		Ω(output).To(ContainSubstring("Name"))
+		Ω(output).To(ContainSubstring("Value"))
+		Ω(output).ToNot(ContainSubstring("------|"))
+		Ω(output).To(ContainSubstring("after\n"))
+	})
+})
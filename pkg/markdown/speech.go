@@ -0,0 +1,90 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	imageRegex      = regexp.MustCompile(`!\[([^\]]*)\]\([^)\s]+\)`)
+	blankLinesRegex = regexp.MustCompile(`\n{3,}`)
+)
+
+// SpeakableText converts Markdown to plain text for reading aloud.
+// Fenced code blocks are replaced with a mention of the code, which is not meant to be heard,
+// and the formatting marks are removed.
+func SpeakableText(text string) string {
+	var lines []string
+	var fence string
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		if inCode {
+			if isClosingFence(line, fence) {
+				inCode = false
+			}
+			continue
+		}
+		if m := fenceInfoRegex.FindStringSubmatch(line); m != nil {
+			inCode = true
+			fence = m[1]
+			lines = append(lines, codeMention(fenceLanguage(m[2])))
+			continue
+		}
+		if ruleRegex.MatchString(line) {
+			lines = append(lines, "")
+			continue
+		}
+		if isTableLine(line) && tableSepRegex.MatchString(line) {
+			continue
+		}
+		lines = append(lines, speakableLine(line))
+	}
+	return strings.TrimSpace(blankLinesRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func codeMention(language string) string {
+	if language == "" {
+		return "See the code in the chat."
+	}
+	return "See the " + language + " code in the chat."
+}
+
+// speakableLine removes the marks from a line of text, ending headings with a period so there is a pause after them
+func speakableLine(line string) string {
+	if m := headingRegex.FindStringSubmatch(line); m != nil {
+		heading := speakableInline(m[2])
+		if last, _ := utf8.DecodeLastRuneInString(heading); heading != "" && !unicode.IsPunct(last) {
+			heading += "."
+		}
+		return heading
+	}
+	if isTableLine(line) {
+		cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+		for i, cell := range cells {
+			cells[i] = speakableInline(cell)
+		}
+		return strings.Join(cells, ", ")
+	}
+	if m := quoteRegex.FindStringSubmatch(line); m != nil {
+		return speakableInline(m[1])
+	}
+	if m := bulletRegex.FindStringSubmatch(line); m != nil {
+		return speakableInline(m[2])
+	}
+	if m := numberedRegex.FindStringSubmatch(line); m != nil {
+		return m[2] + ". " + speakableInline(m[3])
+	}
+	return speakableInline(line)
+}
+
+// speakableInline keeps the text of inline code, emphasis, links, and the alt text of images
+func speakableInline(text string) string {
+	text = imageRegex.ReplaceAllString(text, "$1")
+	text = inlineCodeRegex.ReplaceAllString(text, "$1")
+	text = linkRegex.ReplaceAllString(text, "$1")
+	text = boldRegex.ReplaceAllString(text, "$1$2")
+	text = italicRegex.ReplaceAllString(text, "$1")
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,30 @@
+package markdown_test
+
+import (
+	"github.com/duanemay/chatgpt-cli/pkg/markdown"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SpeakableText", func() {
+	It("should replace code blocks with a mention of the code", func() {
+		text := "Run this:\n```bash\necho hello\nls -la\n```\nThen this:\n~~~\nplain\n~~~\n"
+		Ω(markdown.SpeakableText(text)).To(Equal("Run this:\nSee the bash code in the chat.\nThen this:\nSee the code in the chat."))
+	})
+
+	It("should remove formatting marks", func() {
+		text := "# Summary\n\nUse **bold** and *italic* with `go test`, see [the docs](https://example.com).\n" +
+			"![a diagram](diagram.png)\n\n- first item\n2. second item\n> quoted\n\n---\n"
+		Ω(markdown.SpeakableText(text)).To(Equal("Summary.\n\nUse bold and italic with go test, see the docs.\na diagram\n\nfirst item\n2. second item\nquoted"))
+	})
+
+	It("should only end headings with a period when they don't end with punctuation", func() {
+		Ω(markdown.SpeakableText("## Why?\n\n### To be continued…\n\n#### Café")).To(Equal("Why?\n\nTo be continued…\n\nCafé."))
+	})
+
+	It("should read table rows as lists", func() {
+		text := "| Name | Size |\n|------|------|\n| `a.go` | 10 |\n"
+		Ω(markdown.SpeakableText(text)).To(Equal("Name, Size\na.go, 10"))
+	})
+})
@@ -2,13 +2,13 @@ package os
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
-
-	log "github.com/sirupsen/logrus"
 )
 
-func OpenBrowser(url string) {
+// OpenBrowser opens the url, or file, with the default application
+func OpenBrowser(url string) error {
 	var err error
 
 	switch runtime.GOOS {
@@ -22,7 +22,20 @@ func OpenBrowser(url string) {
 		err = fmt.Errorf("unsupported platform")
 	}
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("unable to open %s: %w", url, err)
 	}
+	return nil
+}
 
+// HasDisplay reports if there is likely a desktop that OpenBrowser can show files on,
+// so not in an SSH session, or on Linux without an X11 or Wayland display
+func HasDisplay() bool {
+	switch runtime.GOOS {
+	case "linux":
+		return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	case "windows", "darwin":
+		return os.Getenv("SSH_CONNECTION") == "" && os.Getenv("SSH_TTY") == ""
+	default:
+		return false
+	}
 }
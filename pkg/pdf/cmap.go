@@ -0,0 +1,90 @@
+package pdf
+
+import "unicode/utf16"
+
+// ParseToUnicode reads the bfchar and bfrange mappings of a ToUnicode CMap, and the length of its codes,
+// or 0 when it has no codespace range
+func ParseToUnicode(cmap []byte) (map[int]string, int) {
+	toUnicode := map[int]string{}
+	codeBytes := 0
+	l := &lexer{data: cmap}
+	var operands []any
+	for {
+		item, err := l.next()
+		if err != nil {
+			return toUnicode, codeBytes
+		}
+		operator, ok := item.(operatorToken)
+		if !ok {
+			operands = append(operands, item)
+			continue
+		}
+
+		switch operator {
+		case "endcodespacerange":
+			if low, ok := firstOperand[[]byte](operands); ok {
+				codeBytes = len(low)
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				code, codeOK := operands[i].([]byte)
+				text, textOK := operands[i+1].([]byte)
+				if codeOK && textOK {
+					toUnicode[cmapCode(code)] = utf16BE(text)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				low, lowOK := operands[i].([]byte)
+				high, highOK := operands[i+1].([]byte)
+				if !lowOK || !highOK {
+					continue
+				}
+				for code := cmapCode(low); code <= cmapCode(high) && code-cmapCode(low) < 0x10000; code++ {
+					offset := code - cmapCode(low)
+					switch text := operands[i+2].(type) {
+					case []byte:
+						// the last byte of the text counts up through the range
+						if len(text) > 0 {
+							next := append([]byte(nil), text...)
+							next[len(next)-1] += byte(offset)
+							toUnicode[code] = utf16BE(next)
+						}
+					case []any:
+						if offset < len(text) {
+							if next, ok := text[offset].([]byte); ok {
+								toUnicode[code] = utf16BE(next)
+							}
+						}
+					}
+				}
+			}
+		}
+		operands = nil
+	}
+}
+
+func firstOperand[T any](operands []any) (T, bool) {
+	var zero T
+	if len(operands) == 0 {
+		return zero, false
+	}
+	value, ok := operands[0].(T)
+	return value, ok
+}
+
+func cmapCode(code []byte) int {
+	value := 0
+	for _, b := range code {
+		value = value<<8 | int(b)
+	}
+	return value
+}
+
+func utf16BE(text []byte) string {
+	units := make([]uint16, 0, len(text)/2)
+	for i := 0; i+1 < len(text); i += 2 {
+		units = append(units, uint16(text[i])<<8|uint16(text[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
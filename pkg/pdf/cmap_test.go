@@ -0,0 +1,31 @@
+package pdf_test
+
+import (
+	"github.com/duanemay/chatgpt-cli/pkg/pdf"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ToUnicode CMaps", func() {
+	DescribeTable("should read the mappings and code length",
+		func(cmap string, toUnicode map[int]string, codeBytes int) {
+			mappings, length := pdf.ParseToUnicode([]byte(cmap))
+			Ω(mappings).To(Equal(toUnicode))
+			Ω(length).To(Equal(codeBytes))
+		},
+		Entry("bfchar", "1 begincodespacerange <0000> <FFFF> endcodespacerange\n2 beginbfchar <0003> <0020> <0011> <00E9> endbfchar",
+			map[int]string{0x03: " ", 0x11: "é"}, 2),
+		Entry("bfrange counting up from the text", "1 beginbfrange <0024> <0026> <0041> endbfrange",
+			map[int]string{0x24: "A", 0x25: "B", 0x26: "C"}, 0),
+		Entry("bfrange with an array of texts", "1 beginbfrange <30> <31> [<0066006C> <D83DDE00>] endbfrange",
+			map[int]string{0x30: "fl", 0x31: "\U0001F600"}, 0),
+		Entry("one byte codes", "1 begincodespacerange <00> <FF> endcodespacerange 1 beginbfchar <93> <201C> endbfchar",
+			map[int]string{0x93: "“"}, 1),
+		Entry("comments and the PostScript around the mappings",
+			"/CIDInit /ProcSet findresource begin % a comment\n12 dict begin begincmap\n/CIDSystemInfo << /Registry (Adobe) >> def\n"+
+				"1 beginbfchar <01> <0041> endbfchar\nendcmap CMapName currentdict /CMap defineresource pop end end",
+			map[int]string{0x01: "A"}, 0),
+		Entry("a bfrange missing its text", "1 beginbfrange <01> <02> endbfrange", map[int]string{}, 0),
+	)
+})
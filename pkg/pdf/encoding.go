@@ -0,0 +1,149 @@
+package pdf
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoding maps the one byte codes of a simple font to characters, or to 0 for codes it leaves undefined
+type Encoding [256]rune
+
+// newEncoding returns the printable ASCII characters, with the characters from 0x80 up
+func newEncoding(high string) *Encoding {
+	var e Encoding
+	for code := ' '; code < 0x7f; code++ {
+		e[code] = code
+	}
+	code := 0x80
+	for _, r := range high {
+		if r != '�' {
+			e[code] = r
+		}
+		code++
+	}
+	return &e
+}
+
+var (
+	// WinAnsiEncoding is Windows code page 1252, the usual encoding of simple fonts
+	WinAnsiEncoding = newEncoding("€�‚ƒ„…†‡ˆ‰Š‹Œ�Ž��‘’“”•–—˜™š›œ�žŸ" + latin1Supplement())
+
+	// MacRomanEncoding is the Mac OS Roman character set, with the currency sign at 0xDB, as PDF defines it
+	MacRomanEncoding = newEncoding(
+		"ÄÅÇÉÑÖÜáàâäãåçéèêëíìîïñóòôöõúùûü" +
+			"†°¢£§•¶ß®©™´¨≠ÆØ∞±≤≥¥µ∂∑∏π∫ªºΩæø" +
+			"¿¡¬√ƒ≈∆«»… ÀÃÕŒœ–—“”‘’÷◊ÿŸ⁄¤‹›ﬁﬂ" +
+			"‡·‚„‰ÂÊÁËÈÍÎÏÌÓÔ�ÒÚÛÙıˆ˜¯˘˙˚¸˝˛ˇ")
+
+	// StandardEncoding is the built-in encoding of the standard Type 1 fonts, such as Helvetica
+	StandardEncoding = standardEncoding()
+)
+
+// latin1Supplement returns the characters from 0xA0 to 0xFF of ISO 8859-1
+func latin1Supplement() string {
+	var sb strings.Builder
+	for r := rune(0xA0); r <= 0xFF; r++ {
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func standardEncoding() *Encoding {
+	e := newEncoding("")
+	e['\''] = '’'
+	e['`'] = '‘'
+	high := map[int]rune{
+		0xA1: '¡', 0xA2: '¢', 0xA3: '£', 0xA4: '⁄', 0xA5: '¥', 0xA6: 'ƒ', 0xA7: '§', 0xA8: '¤',
+		0xA9: '\'', 0xAA: '“', 0xAB: '«', 0xAC: '‹', 0xAD: '›', 0xAE: 'ﬁ', 0xAF: 'ﬂ',
+		0xB1: '–', 0xB2: '†', 0xB3: '‡', 0xB4: '·', 0xB6: '¶', 0xB7: '•', 0xB8: '‚', 0xB9: '„',
+		0xBA: '”', 0xBB: '»', 0xBC: '…', 0xBD: '‰', 0xBF: '¿',
+		0xC1: '`', 0xC2: '´', 0xC3: 'ˆ', 0xC4: '˜', 0xC5: '¯', 0xC6: '˘', 0xC7: '˙', 0xC8: '¨',
+		0xCA: '˚', 0xCB: '¸', 0xCD: '˝', 0xCE: '˛', 0xCF: 'ˇ', 0xD0: '—',
+		0xE1: 'Æ', 0xE3: 'ª', 0xE8: 'Ł', 0xE9: 'Ø', 0xEA: 'Œ', 0xEB: 'º',
+		0xF1: 'æ', 0xF5: 'ı', 0xF8: 'ł', 0xF9: 'ø', 0xFA: 'œ', 0xFB: 'ß',
+	}
+	for code, r := range high {
+		e[code] = r
+	}
+	return e
+}
+
+// glyphNames names the characters of the Latin fonts, for the Differences of an encoding
+var glyphNames = map[string]rune{}
+
+func init() {
+	ascii := strings.Fields("space exclam quotedbl numbersign dollar percent ampersand quotesingle parenleft parenright " +
+		"asterisk plus comma hyphen period slash zero one two three four five six seven eight nine colon semicolon " +
+		"less equal greater question at")
+	for i, glyph := range ascii {
+		glyphNames[glyph] = ' ' + rune(i)
+	}
+	for i, glyph := range strings.Fields("bracketleft backslash bracketright asciicircum underscore grave") {
+		glyphNames[glyph] = '[' + rune(i)
+	}
+	for i, glyph := range strings.Fields("braceleft bar braceright asciitilde") {
+		glyphNames[glyph] = '{' + rune(i)
+	}
+	latin1 := strings.Fields("nbspace exclamdown cent sterling currency yen brokenbar section dieresis copyright " +
+		"ordfeminine guillemotleft logicalnot sfthyphen registered macron degree plusminus twosuperior threesuperior " +
+		"acute mu paragraph periodcentered cedilla onesuperior ordmasculine guillemotright onequarter onehalf " +
+		"threequarters questiondown Agrave Aacute Acircumflex Atilde Adieresis Aring AE Ccedilla Egrave Eacute " +
+		"Ecircumflex Edieresis Igrave Iacute Icircumflex Idieresis Eth Ntilde Ograve Oacute Ocircumflex Otilde " +
+		"Odieresis multiply Oslash Ugrave Uacute Ucircumflex Udieresis Yacute Thorn germandbls agrave aacute " +
+		"acircumflex atilde adieresis aring ae ccedilla egrave eacute ecircumflex edieresis igrave iacute " +
+		"icircumflex idieresis eth ntilde ograve oacute ocircumflex otilde odieresis divide oslash ugrave uacute " +
+		"ucircumflex udieresis yacute thorn ydieresis")
+	for i, glyph := range latin1 {
+		glyphNames[glyph] = 0xA0 + rune(i)
+	}
+	for glyph, r := range map[string]rune{
+		"quoteleft": '‘', "quoteright": '’', "quotesinglbase": '‚', "quotedblleft": '“', "quotedblright": '”',
+		"quotedblbase": '„', "guilsinglleft": '‹', "guilsinglright": '›', "endash": '–', "emdash": '—',
+		"bullet": '•', "ellipsis": '…', "dagger": '†', "daggerdbl": '‡', "perthousand": '‰', "trademark": '™',
+		"Euro": '€', "florin": 'ƒ', "fraction": '⁄', "minus": '−', "fi": 'ﬁ', "fl": 'ﬂ', "circumflex": 'ˆ',
+		"tilde": '˜', "breve": '˘', "dotaccent": '˙', "ring": '˚', "hungarumlaut": '˝', "ogonek": '˛',
+		"caron": 'ˇ', "dotlessi": 'ı', "Lslash": 'Ł', "lslash": 'ł', "OE": 'Œ', "oe": 'œ', "Scaron": 'Š',
+		"scaron": 'š', "Zcaron": 'Ž', "zcaron": 'ž', "Ydieresis": 'Ÿ',
+	} {
+		glyphNames[glyph] = r
+	}
+}
+
+// glyphRune returns the character a glyph name stands for: a named Latin character, a single letter such as A,
+// or a code point such as uni20AC or u1F600. Returns 0 for other names.
+func glyphRune(glyph string) rune {
+	if r, ok := glyphNames[glyph]; ok {
+		return r
+	}
+	if r, size := utf8.DecodeRuneInString(glyph); size == len(glyph) && r != utf8.RuneError {
+		return r
+	}
+	for _, prefix := range []string{"uni", "u"} {
+		if hex, ok := strings.CutPrefix(glyph, prefix); ok && len(hex) >= 4 && len(hex) <= 6 {
+			if code, err := strconv.ParseUint(hex, 16, 32); err == nil && utf8.ValidRune(rune(code)) {
+				return rune(code)
+			}
+		}
+	}
+	return 0
+}
+
+// withDifferences returns a copy of the encoding, with the codes listed in a Differences array changed to the
+// glyphs named after them. Each number in the array is the code of the name following it.
+func (e *Encoding) withDifferences(differences []any) *Encoding {
+	changed := *e
+	code := 0
+	for _, item := range differences {
+		switch item := item.(type) {
+		case int:
+			code = item
+		case string:
+			if code >= 0 && code < len(changed) {
+				changed[code] = glyphRune(item)
+			}
+			code++
+		}
+	}
+	return &changed
+}
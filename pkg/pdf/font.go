@@ -0,0 +1,220 @@
+package pdf
+
+import (
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Font decodes the strings shown in a font to text
+type Font struct {
+	// CodeBytes is the length of a character code, 1 for simple fonts, and usually 2 for composite fonts
+	CodeBytes int
+	// Encoding maps the codes of a simple font to text, when they are missing from ToUnicode
+	Encoding *Encoding
+	// ToUnicode maps character codes to text, from the ToUnicode CMap of the font
+	ToUnicode map[int]string
+	// Widths maps character codes to their widths, in thousandths of the font size, or is nil when they are not
+	// known, with DefaultWidth for the codes missing from it
+	Widths       map[int]float64
+	DefaultWidth float64
+}
+
+// defaultFont is used until a content stream selects a font, and for fonts that can't be read
+var defaultFont = Font{CodeBytes: 1, Encoding: WinAnsiEncoding}
+
+// Decode maps each character code to text. Codes missing from a simple font's ToUnicode CMap are read with its
+// encoding, and those missing from a composite font's are dropped, as they are glyph numbers.
+func (f Font) Decode(s []byte) string {
+	var sb strings.Builder
+	for _, code := range f.codes(s) {
+		if text, ok := f.ToUnicode[code]; ok {
+			sb.WriteString(text)
+		} else if f.CodeBytes <= 1 && f.Encoding != nil && f.Encoding[code] != 0 {
+			sb.WriteRune(f.Encoding[code])
+		}
+	}
+	return sb.String()
+}
+
+// width returns the width of a string, in thousandths of the font size, and whether the font's widths are known
+func (f Font) width(s []byte) (float64, bool) {
+	if f.Widths == nil {
+		return 0, false
+	}
+	total := 0.0
+	for _, code := range f.codes(s) {
+		if width, ok := f.Widths[code]; ok {
+			total += width
+		} else {
+			total += f.DefaultWidth
+		}
+	}
+	return total, true
+}
+
+// codes splits a string into its character codes
+func (f Font) codes(s []byte) []int {
+	codeBytes := max(f.CodeBytes, 1)
+	var codes []int
+	for i := 0; i+codeBytes <= len(s); i += codeBytes {
+		code := 0
+		for _, b := range s[i : i+codeBytes] {
+			code = code<<8 | int(b)
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// readFont reads a font dictionary, with its encoding and ToUnicode CMap
+func readFont(ctx *model.Context, fontDict types.Dict) Font {
+	font := defaultFont
+	subtype := ""
+	if fontDict.Subtype() != nil {
+		subtype = *fontDict.Subtype()
+	}
+	if subtype == "Type0" {
+		font = Font{CodeBytes: 2}
+		font.Widths, font.DefaultWidth = compositeFontWidths(ctx, fontDict)
+	} else {
+		font.Encoding = simpleFontEncoding(ctx, fontDict, subtype)
+		font.Widths = simpleFontWidths(ctx, fontDict)
+	}
+
+	toUnicode, _, err := ctx.DereferenceStreamDict(fontDict["ToUnicode"])
+	if err != nil || toUnicode == nil || toUnicode.Decode() != nil {
+		return font
+	}
+	var codeBytes int
+	font.ToUnicode, codeBytes = ParseToUnicode(toUnicode.Content)
+	// simple fonts always have one byte codes, whatever their CMap says
+	if subtype == "Type0" && codeBytes > 0 {
+		font.CodeBytes = codeBytes
+	}
+	return font
+}
+
+// simpleFontEncoding returns the encoding named by a simple font, or its base encoding with its Differences.
+// Type 1 fonts without one use the standard encoding, and other fonts WinAnsi.
+func simpleFontEncoding(ctx *model.Context, fontDict types.Dict, subtype string) *Encoding {
+	base := WinAnsiEncoding
+	if subtype == "Type1" || subtype == "MMType1" {
+		base = StandardEncoding
+	}
+
+	encoding, err := ctx.Dereference(fontDict["Encoding"])
+	if err != nil {
+		return base
+	}
+	switch encoding := encoding.(type) {
+	case types.Name:
+		return namedEncoding(string(encoding), base)
+	case types.Dict:
+		if baseEncoding := encoding.NameEntry("BaseEncoding"); baseEncoding != nil {
+			base = namedEncoding(*baseEncoding, base)
+		}
+		differences, err := ctx.DereferenceArray(encoding["Differences"])
+		if err != nil {
+			return base
+		}
+		var items []any
+		for _, item := range differences {
+			item, _ = ctx.Dereference(item)
+			switch item := item.(type) {
+			case types.Integer:
+				items = append(items, int(item))
+			case types.Name:
+				items = append(items, string(item))
+			}
+		}
+		return base.withDifferences(items)
+	}
+	return base
+}
+
+func namedEncoding(name string, base *Encoding) *Encoding {
+	switch name {
+	case "WinAnsiEncoding":
+		return WinAnsiEncoding
+	case "MacRomanEncoding":
+		return MacRomanEncoding
+	case "StandardEncoding":
+		return StandardEncoding
+	}
+	return base
+}
+
+// simpleFontWidths reads the Widths of a simple font, which start at its FirstChar
+func simpleFontWidths(ctx *model.Context, fontDict types.Dict) map[int]float64 {
+	widths, err := ctx.DereferenceArray(fontDict["Widths"])
+	if err != nil || widths == nil {
+		return nil
+	}
+	firstChar, err := ctx.DereferenceInteger(fontDict["FirstChar"])
+	if err != nil || firstChar == nil {
+		return nil
+	}
+	result := map[int]float64{}
+	for i, width := range widths {
+		if value, err := ctx.DereferenceNumber(width); err == nil {
+			result[firstChar.Value()+i] = value
+		}
+	}
+	return result
+}
+
+// compositeFontWidths reads the W array of the descendant of a composite font, which lists the widths of runs of
+// codes, as a first code followed by an array of widths, or first and last codes followed by their width,
+// and the DW width of the other codes, 1000 when it is not given
+func compositeFontWidths(ctx *model.Context, fontDict types.Dict) (map[int]float64, float64) {
+	descendants, err := ctx.DereferenceArray(fontDict["DescendantFonts"])
+	if err != nil || len(descendants) == 0 {
+		return nil, 0
+	}
+	descendant, err := ctx.DereferenceDict(descendants[0])
+	if err != nil || descendant == nil {
+		return nil, 0
+	}
+	defaultWidth := 1000.0
+	if value, err := ctx.DereferenceNumber(descendant["DW"]); err == nil && descendant["DW"] != nil {
+		defaultWidth = value
+	}
+	result := map[int]float64{}
+	w, err := ctx.DereferenceArray(descendant["W"])
+	if err != nil {
+		return result, defaultWidth
+	}
+	for i := 0; i+1 < len(w); {
+		first, err := ctx.DereferenceInteger(w[i])
+		if err != nil || first == nil {
+			break
+		}
+		if run, err := ctx.DereferenceArray(w[i+1]); err == nil && run != nil {
+			for j, width := range run {
+				if value, err := ctx.DereferenceNumber(width); err == nil {
+					result[first.Value()+j] = value
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			break
+		}
+		last, err := ctx.DereferenceInteger(w[i+1])
+		if err != nil || last == nil {
+			break
+		}
+		width, err := ctx.DereferenceNumber(w[i+2])
+		if err != nil {
+			break
+		}
+		for code := first.Value(); code <= last.Value() && code-first.Value() < 0x10000; code++ {
+			result[code] = width
+		}
+		i += 3
+	}
+	return result, defaultWidth
+}
@@ -0,0 +1,29 @@
+package pdf_test
+
+import (
+	"github.com/duanemay/chatgpt-cli/pkg/pdf"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fonts", func() {
+	DescribeTable("should decode the codes of a font",
+		func(font pdf.Font, codes string, text string) {
+			Ω(font.Decode([]byte(codes))).To(Equal(text))
+		},
+		Entry("WinAnsi quotes, dashes, and the euro", pdf.Font{CodeBytes: 1, Encoding: pdf.WinAnsiEncoding},
+			"\x93Caf\xe9\x94 \x96 \x80 5 \x97 it\x92s", "“Café” – € 5 — it’s"),
+		Entry("WinAnsi codes it leaves undefined", pdf.Font{CodeBytes: 1, Encoding: pdf.WinAnsiEncoding},
+			"a\x81b\x8dc\x01", "abc"),
+		Entry("MacRoman", pdf.Font{CodeBytes: 1, Encoding: pdf.MacRomanEncoding},
+			"\xd2Caf\x8e\xd3 \xd0 \xdb \xde", "“Café” – ¤ ﬁ"),
+		Entry("the standard encoding's quotes", pdf.Font{CodeBytes: 1, Encoding: pdf.StandardEncoding},
+			"`it's\xaa \xb1 \xd0\xba", "‘it’s“ – —”"),
+		Entry("ToUnicode before the encoding", pdf.Font{CodeBytes: 1, Encoding: pdf.WinAnsiEncoding, ToUnicode: map[int]string{'a': "ä"}},
+			"ab", "äb"),
+		Entry("composite fonts, dropping glyphs missing from ToUnicode",
+			pdf.Font{CodeBytes: 2, ToUnicode: map[int]string{0x24: "A", 0x0102: "ﬂ"}}, "\x00\x24\x01\x02\x00\x99\x00", "Aﬂ"),
+		Entry("one byte codes when the length is not set", pdf.Font{Encoding: pdf.WinAnsiEncoding}, "hi", "hi"),
+	)
+})
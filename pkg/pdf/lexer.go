@@ -0,0 +1,193 @@
+package pdf
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+type (
+	nameToken     string
+	operatorToken string
+)
+
+// lexer reads the operands and operators of a content stream or CMap. Strings are read as []byte, numbers as
+// float64, and arrays as []any; dictionaries are skipped, and read as nil.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func isWhitespace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// next returns the next operand or operator, or io.EOF at the end of the data
+func (l *lexer) next() (any, error) {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isWhitespace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == '(':
+			return l.literalString(), nil
+		case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+			l.pos += 2
+			return nil, l.skipUntil(">>")
+		case c == '<':
+			return l.hexString(), nil
+		case c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+			l.pos += 2
+			return operatorToken(">>"), nil
+		case c == '[':
+			l.pos++
+			return l.array()
+		case c == ']' || c == '{' || c == '}' || c == ')' || c == '>':
+			l.pos++
+			return operatorToken(c), nil
+		case c == '/':
+			l.pos++
+			return nameToken(l.regular()), nil
+		default:
+			word := l.regular()
+			if number, err := strconv.ParseFloat(word, 64); err == nil {
+				return number, nil
+			}
+			if word == "ID" {
+				l.skipInlineImage()
+			}
+			return operatorToken(word), nil
+		}
+	}
+	return nil, io.EOF
+}
+
+// regular reads a run of characters up to whitespace or a delimiter
+func (l *lexer) regular() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isWhitespace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// literalString reads a (string), with its escapes and balanced parentheses
+func (l *lexer) literalString() []byte {
+	var s []byte
+	depth := 0
+	for l.pos++; l.pos < len(l.data); l.pos++ {
+		c := l.data[l.pos]
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				l.pos++
+				return s
+			}
+			depth--
+		case '\\':
+			l.pos++
+			if l.pos >= len(l.data) {
+				return s
+			}
+			c = l.data[l.pos]
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// a line continuation
+				if c == '\r' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					octal := int(c - '0')
+					for i := 0; i < 2 && l.pos+1 < len(l.data) && l.data[l.pos+1] >= '0' && l.data[l.pos+1] <= '7'; i++ {
+						l.pos++
+						octal = octal*8 + int(l.data[l.pos]-'0')
+					}
+					c = byte(octal)
+				}
+			}
+		}
+		s = append(s, c)
+	}
+	return s
+}
+
+// hexString reads a <hex string>, where a missing last digit is 0
+func (l *lexer) hexString() []byte {
+	var s []byte
+	high, odd := byte(0), false
+	for l.pos++; l.pos < len(l.data) && l.data[l.pos] != '>'; l.pos++ {
+		digit, err := strconv.ParseUint(string(l.data[l.pos]), 16, 8)
+		if err != nil {
+			continue
+		}
+		if odd {
+			s = append(s, high<<4|byte(digit))
+		} else {
+			high = byte(digit)
+		}
+		odd = !odd
+	}
+	if odd {
+		s = append(s, high<<4)
+	}
+	l.pos++
+	return s
+}
+
+func (l *lexer) array() ([]any, error) {
+	var items []any
+	for {
+		item, err := l.next()
+		if err != nil {
+			return items, err
+		}
+		if item == operatorToken("]") {
+			return items, nil
+		}
+		items = append(items, item)
+	}
+}
+
+// skipUntil skips operands until the operator, such as the end of a dictionary
+func (l *lexer) skipUntil(operator operatorToken) error {
+	for {
+		item, err := l.next()
+		if err != nil || item == operator {
+			return err
+		}
+	}
+}
+
+// skipInlineImage skips the data of an inline image, up to the EI that ends it
+func (l *lexer) skipInlineImage() {
+	for l.pos++; l.pos+2 <= len(l.data); l.pos++ {
+		if l.data[l.pos] == 'E' && l.data[l.pos+1] == 'I' && isWhitespace(l.data[l.pos-1]) &&
+			(l.pos+2 == len(l.data) || isWhitespace(l.data[l.pos+2])) {
+			l.pos += 2
+			return
+		}
+	}
+	l.pos = len(l.data)
+}
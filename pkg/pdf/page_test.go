@@ -0,0 +1,90 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/duanemay/chatgpt-cli/pkg/pdf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// stream is a PDF stream object, with the entries of its dictionary
+func stream(entries string, content string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", entries, len(content), content)
+}
+
+// onePagePDF builds a PDF with a page drawing the content, with the font F1 and form X1, and any other objects,
+// numbered from 6
+func onePagePDF(font string, content string, form string, others ...string) []byte {
+	objects := append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> /XObject << /X1 6 0 R >> >> /Contents 5 0 R >>",
+		font,
+		stream("", content),
+		form,
+	}, others...)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		_, _ = fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	_, _ = fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		_, _ = fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	_, _ = fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+var _ = Describe("Pages", func() {
+	const (
+		helvetica = "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica"
+		noForm    = "<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8 /Length 1 >>\nstream\n\x00\nendstream"
+	)
+
+	DescribeTable("should read the text of a page",
+		func(data []byte, text string) {
+			conf := model.NewDefaultConfiguration()
+			conf.ValidationMode = model.ValidationRelaxed
+			ctx, err := api.ReadValidateAndOptimize(bytes.NewReader(data), conf)
+			Ω(err).ToNot(HaveOccurred())
+			Ω(pdf.PageText(ctx, 1)).To(Equal(text))
+		},
+		Entry("with the standard encoding of a Type 1 font without one",
+			onePagePDF(helvetica+" >>", "BT /F1 12 Tf (it's) Tj ET", noForm), "it’s"),
+		Entry("with the WinAnsi encoding",
+			onePagePDF(helvetica+" /Encoding /WinAnsiEncoding >>", "BT /F1 12 Tf <93 4F 4B 94 20 96 20 80 35> Tj ET", noForm), "“OK” – €5"),
+		Entry("with the MacRoman encoding",
+			onePagePDF(helvetica+" /Encoding /MacRomanEncoding >>", "BT /F1 12 Tf <D2 4F 4B D3 20 D0 20 8E> Tj ET", noForm), "“OK” – é"),
+		Entry("with the Differences of an encoding",
+			onePagePDF(helvetica+" /Encoding 7 0 R >>", "BT /F1 12 Tf <01 02 41 93 80> Tj ET", noForm,
+				"<< /Type /Encoding /BaseEncoding /WinAnsiEncoding /Differences [1 /Euro /uni2192 65 /eacute] >>"), "€→é“€"),
+		Entry("with a ToUnicode CMap before the encoding",
+			onePagePDF(helvetica+" /Encoding /WinAnsiEncoding /ToUnicode 7 0 R >>", "BT /F1 12 Tf (ab) Tj ET", noForm,
+				stream("", "1 beginbfchar <61> <00E4> endbfchar")), "äb"),
+		Entry("with a composite font",
+			onePagePDF("<< /Type /Font /Subtype /Type0 /BaseFont /Example /Encoding /Identity-H /DescendantFonts [8 0 R] /ToUnicode 7 0 R >>",
+				"BT /F1 12 Tf <00240025> Tj ET", noForm,
+				stream("", "1 begincodespacerange <0000> <FFFF> endcodespacerange 1 beginbfrange <0024> <0025> <0048> endbfrange"),
+				"<< /Type /Font /Subtype /CIDFontType2 /BaseFont /Example /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor 9 0 R >>",
+				"<< /Type /FontDescriptor /FontName /Example /Flags 32 /FontBBox [0 0 1000 1000] /ItalicAngle 0 /Ascent 800 /Descent -200 /CapHeight 700 /StemV 80 >>"), "HI"),
+		Entry("drawn by a form, in the form's fonts",
+			onePagePDF(helvetica+" >>", "BT /F1 12 Tf (Page) Tj ET /X1 Do", stream(
+				"/Type /XObject /Subtype /Form /BBox [0 0 100 100] /Resources << /Font << /F1 7 0 R >> >>",
+				"BT /F1 12 Tf 0 -14 Td <93 66 6F 72 6D 94> Tj ET"),
+				helvetica+" /Encoding /WinAnsiEncoding >>"), "Page\n“form”"),
+		Entry("drawing itself as a form, until it is too deep",
+			onePagePDF(helvetica+" >>", "/X1 Do", stream(
+				"/Type /XObject /Subtype /Form /BBox [0 0 100 100] /Resources << /Font << /F1 4 0 R >> /XObject << /X1 6 0 R >> >>",
+				"BT /F1 12 Tf (x) Tj ET /X1 Do")), "x x x x x x x x"),
+	)
+})
@@ -0,0 +1,19 @@
+package pdf_test
+
+import (
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPDF(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "PDF Suite")
+}
+
+var _ = BeforeSuite(func() {
+	api.DisableConfigDir()
+})
@@ -0,0 +1,277 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+const (
+	// maxFormDepth is how deeply forms drawn by other forms are followed for their text
+	maxFormDepth = 8
+	// wordGap is how far text moves along a line, in thousandths of the font size, for it to be read as a space
+	wordGap = 200
+)
+
+// textExtractor reads the text shown on a page, in the order it is drawn, starting a new line wherever the text
+// moves up or down the page, and adding a space wherever it moves along the line. Text drawn as vector outlines,
+// or in images, can't be read.
+type textExtractor struct {
+	ctx *model.Context
+	// fonts are used in place of the fonts of the resources, when there is no document
+	fonts map[string]Font
+	sb    strings.Builder
+
+	// endX and endY are where the last text shown ended, when shown is set. Without the widths of its font,
+	// endKnown is not set, and only the line is known.
+	endX, endY float64
+	shown      bool
+	endKnown   bool
+	// newLine is set by the operators that move to the next line, which may not move when the leading is 0
+	newLine bool
+	// moved is set when the text is positioned, to space text shown in a font without widths
+	moved bool
+}
+
+// textState is the position and font of the text being shown, in text space, scaled by the text matrix
+type textState struct {
+	font    Font
+	size    float64
+	leading float64
+	// lineX and lineY are the start of the line, and x is where the next text is shown along it
+	lineX, lineY, x float64
+	scaleX, scaleY  float64
+}
+
+func newTextState() textState {
+	return textState{font: defaultFont, size: 1, scaleX: 1, scaleY: 1}
+}
+
+// moveLine starts a new line, offset from the start of the current one
+func (s *textState) moveLine(tx float64, ty float64) {
+	s.lineX += tx * s.scaleX
+	s.lineY += ty * s.scaleY
+	s.x = s.lineX
+}
+
+// advance moves along the line, by thousandths of the font size
+func (s *textState) advance(thousandths float64) {
+	s.x += thousandths / 1000 * s.size * s.scaleX
+}
+
+// PageText returns the text of a page
+func PageText(ctx *model.Context, pageNumber int) (string, error) {
+	content, err := pdfcpu.ExtractPageContent(ctx, pageNumber)
+	if err != nil {
+		return "", fmt.Errorf("text extract error: %w", err)
+	}
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", fmt.Errorf("text extract error: %w", err)
+	}
+	pageDict, _, inherited, err := ctx.PageDict(pageNumber, false)
+	if err != nil {
+		return "", fmt.Errorf("text extract error: %w", err)
+	}
+	resources, err := ctx.DereferenceDict(pageDict["Resources"])
+	if err != nil || resources == nil {
+		resources = inherited.Resources
+	}
+
+	e := &textExtractor{ctx: ctx}
+	e.extract(data, resources, 0)
+	return e.text(), nil
+}
+
+// ContentText returns the text shown by a content stream, in the fonts it names, without the forms it draws
+func ContentText(content []byte, fonts map[string]Font) string {
+	e := &textExtractor{fonts: fonts}
+	e.extract(content, nil, 0)
+	return e.text()
+}
+
+// text returns the lines read, without surrounding spaces
+func (e *textExtractor) text() string {
+	lines := strings.Split(e.sb.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func (e *textExtractor) breakLine() {
+	if e.sb.Len() > 0 && !strings.HasSuffix(e.sb.String(), "\n") {
+		e.sb.WriteString("\n")
+	}
+}
+
+func (e *textExtractor) space() {
+	if text := e.sb.String(); text != "" && !strings.HasSuffix(text, " ") && !strings.HasSuffix(text, "\n") {
+		e.sb.WriteString(" ")
+	}
+}
+
+// show writes a string shown at the current position, after a line break or a space when it has moved away
+// from the end of the last text. When the widths of the last text are not known, the end can't be found, so
+// a space is added whenever the text was positioned along the line.
+func (e *textExtractor) show(s []byte, state *textState) {
+	lineHeight := max(math.Abs(state.size*state.scaleY), 1)
+	if e.shown {
+		switch {
+		case e.newLine || math.Abs(state.lineY-e.endY) > lineHeight/2:
+			e.breakLine()
+		case !e.endKnown:
+			if e.moved {
+				e.space()
+			}
+		case state.x-e.endX > wordGap/1000.0*math.Abs(state.size*state.scaleX) || state.x < e.endX-lineHeight:
+			e.space()
+		}
+	}
+	e.sb.WriteString(state.font.Decode(s))
+
+	width, known := state.font.width(s)
+	state.advance(width)
+	e.endX, e.endY, e.shown, e.endKnown, e.newLine, e.moved = state.x, state.lineY, true, known, false, false
+}
+
+// extract reads the text shown by a content stream, and by the forms it draws
+func (e *textExtractor) extract(content []byte, resources types.Dict, depth int) {
+	l := &lexer{data: content}
+	fonts := map[string]Font{}
+	state := newTextState()
+	var operands []any
+	number := func(i int) float64 {
+		if i < len(operands) {
+			value, _ := operands[i].(float64)
+			return value
+		}
+		return 0
+	}
+	nextLine := func() {
+		state.moveLine(0, -state.leading)
+		e.newLine = true
+	}
+	for {
+		item, err := l.next()
+		if err != nil {
+			return
+		}
+		operator, ok := item.(operatorToken)
+		if !ok {
+			operands = append(operands, item)
+			continue
+		}
+
+		switch operator {
+		case "BT":
+			font, size, leading := state.font, state.size, state.leading
+			state = newTextState()
+			state.font, state.size, state.leading = font, size, leading
+			e.moved = true
+		case "Tf":
+			if name, ok := firstOperand[nameToken](operands); ok {
+				if _, read := fonts[string(name)]; !read {
+					fonts[string(name)] = e.font(resources, string(name))
+				}
+				state.font = fonts[string(name)]
+			}
+			state.size = number(1)
+		case "TL":
+			state.leading = number(0)
+		case "Td":
+			state.moveLine(number(0), number(1))
+			e.moved = true
+		case "TD":
+			state.leading = -number(1)
+			state.moveLine(number(0), number(1))
+			e.moved = true
+		case "Tm":
+			if len(operands) == 6 {
+				state.scaleX, state.scaleY = number(0), number(3)
+				state.lineX, state.lineY, state.x = number(4), number(5), number(4)
+				e.moved = true
+			}
+		case "T*":
+			nextLine()
+		case "Tj":
+			if s, ok := firstOperand[[]byte](operands); ok {
+				e.show(s, &state)
+			}
+		case "'", "\"":
+			nextLine()
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].([]byte); ok {
+					e.show(s, &state)
+				}
+			}
+		case "TJ":
+			items, _ := firstOperand[[]any](operands)
+			for _, item := range items {
+				switch item := item.(type) {
+				case []byte:
+					e.show(item, &state)
+				case float64:
+					state.advance(-item)
+					if !e.endKnown && item < -wordGap {
+						e.space()
+					}
+				}
+			}
+		case "Do":
+			if name, ok := firstOperand[nameToken](operands); ok && depth < maxFormDepth {
+				e.form(resources, string(name), depth)
+			}
+		}
+		operands = nil
+	}
+}
+
+// form reads the text of a form XObject, which is drawn with its own resources, or those of the page
+func (e *textExtractor) form(resources types.Dict, name string, depth int) {
+	if e.ctx == nil || resources == nil {
+		return
+	}
+	xObjects, err := e.ctx.DereferenceDict(resources["XObject"])
+	if err != nil || xObjects == nil {
+		return
+	}
+	stream, _, err := e.ctx.DereferenceStreamDict(xObjects[name])
+	if err != nil || stream == nil || stream.Subtype() == nil || *stream.Subtype() != "Form" {
+		return
+	}
+	if err := stream.Decode(); err != nil {
+		return
+	}
+	if formResources, err := e.ctx.DereferenceDict(stream.Dict["Resources"]); err == nil && formResources != nil {
+		resources = formResources
+	}
+	e.extract(stream.Content, resources, depth+1)
+}
+
+// font returns the font named in the resources
+func (e *textExtractor) font(resources types.Dict, name string) Font {
+	if e.ctx == nil {
+		if font, ok := e.fonts[name]; ok {
+			return font
+		}
+		return defaultFont
+	}
+	if resources == nil {
+		return defaultFont
+	}
+	fonts, err := e.ctx.DereferenceDict(resources["Font"])
+	if err != nil || fonts == nil {
+		return defaultFont
+	}
+	fontDict, err := e.ctx.DereferenceDict(fonts[name])
+	if err != nil || fontDict == nil {
+		return defaultFont
+	}
+	return readFont(e.ctx, fontDict)
+}
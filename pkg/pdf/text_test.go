@@ -0,0 +1,42 @@
+package pdf_test
+
+import (
+	"github.com/duanemay/chatgpt-cli/pkg/pdf"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Content Streams", func() {
+	fonts := map[string]pdf.Font{
+		"F1": {CodeBytes: 1, Encoding: pdf.WinAnsiEncoding},
+		"F2": {CodeBytes: 2, ToUnicode: map[int]string{1: "H", 2: "i"}},
+		"F3": {CodeBytes: 1, Encoding: pdf.WinAnsiEncoding, Widths: map[int]float64{'i': 250}, DefaultWidth: 500},
+	}
+
+	DescribeTable("should read the text shown",
+		func(content string, text string) {
+			Ω(pdf.ContentText([]byte(content), fonts)).To(Equal(text))
+		},
+		Entry("a string", "BT /F1 12 Tf 72 720 Td (Hello) Tj ET", "Hello"),
+		Entry("kerning, with a space for a large gap", "BT /F1 12 Tf [(Hel) -20 (lo) -400 (world)] TJ ET", "Hello world"),
+		Entry("moving down to a new line", "BT /F1 12 Tf (one) Tj 0 -14 Td (two) Tj 0 -14 TD (three) Tj ET", "one\ntwo\nthree"),
+		Entry("moving along the line", "BT /F1 12 Tf (one) Tj 40 0 Td (two) Tj ET", "one two"),
+		Entry("the next line operators", "BT /F1 12 Tf 14 TL (one) Tj T* (two) Tj (three) ' 0 0 (four) \" ET", "one\ntwo\nthree\nfour"),
+		Entry("each character positioned after the last, in a font with widths",
+			"BT /F3 10 Tf 0 0 Td (H) Tj 5 0 Td (i) Tj 2.5 0 Td (m) Tj 8 0 Td (o) Tj 5 0 Td (m) Tj ET", "Him om"),
+		Entry("a new text matrix", "BT /F1 12 Tf 1 0 0 1 72 720 Tm (one) Tj 1 0 0 1 72 700 Tm (two) Tj ET", "one\ntwo"),
+		Entry("separate text objects", "BT /F1 12 Tf (one) Tj ET BT (two) Tj ET", "one two"),
+		Entry("escapes and balanced parentheses, dropping controls without a glyph", `BT /F1 12 Tf (a\(b\) (c) \\ \101\102 tab\there) Tj ET`, "a(b) (c) \\ AB tabhere"),
+		Entry("a line continuation", "BT /F1 12 Tf (con\\\ntinued) Tj ET", "continued"),
+		Entry("hex strings, with a missing last digit", "BT /F1 12 Tf <48 69 2> Tj ET", "Hi"),
+		Entry("WinAnsi codes", "BT /F1 12 Tf <93 43 61 66 E9 94 20 96 20 80> Tj ET", "“Café” – €"),
+		Entry("composite fonts", "BT /F2 12 Tf <00010002> Tj ET", "Hi"),
+		Entry("fonts that are not known", "BT /F9 12 Tf (plain) Tj ET", "plain"),
+		Entry("comments", "BT /F1 12 Tf % (hidden) Tj\n(shown) Tj ET", "shown"),
+		Entry("marked content dictionaries", "/Span << /ActualText (x) /MCID 0 >> BDC BT /F1 12 Tf (shown) Tj ET EMC", "shown"),
+		Entry("inline images", "BI /W 2 /H 1 /BPC 8 /CS /G ID \x00(Tj)\xff EI BT /F1 12 Tf (after) Tj ET", "after"),
+		Entry("drawing operators", "0 0 m 100 100 l S 1 0 0 RG BT /F1 12 Tf (text) Tj ET", "text"),
+		Entry("nothing", "", ""),
+	)
+})
@@ -0,0 +1,148 @@
+package preview
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// Protocol is a way of showing images in a terminal
+type Protocol string
+
+const (
+	// Kitty is the Kitty graphics protocol, also supported by Ghostty
+	Kitty Protocol = "kitty"
+	// ITerm2 is the iTerm2 inline images protocol, also supported by WezTerm
+	ITerm2 Protocol = "iterm2"
+	// Sixel is the DEC Sixel graphics format
+	Sixel Protocol = "sixel"
+	// HalfBlock draws the image with Unicode half blocks and 24-bit colors, which most terminals support
+	HalfBlock Protocol = "half-block"
+)
+
+const (
+	// kittyChunkSize is the largest base64 payload allowed in a single Kitty graphics escape sequence
+	kittyChunkSize = 4096
+	// sixelPixelsPerColumn approximates the width of a terminal cell, to size Sixel images
+	sixelPixelsPerColumn = 8
+)
+
+// DetectProtocol picks the best protocol the terminal supports, from its environment variables
+func DetectProtocol(getenv func(string) string) Protocol {
+	term := getenv("TERM")
+	program := getenv("TERM_PROGRAM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty" || program == "ghostty":
+		return Kitty
+	case program == "iTerm.app" || program == "WezTerm" || getenv("LC_TERMINAL") == "iTerm2":
+		return ITerm2
+	case strings.Contains(term, "sixel") || strings.HasPrefix(term, "foot") || term == "mlterm" || program == "mlterm":
+		return Sixel
+	default:
+		return HalfBlock
+	}
+}
+
+// Render writes the image to a terminal using the protocol, at most columns wide
+func Render(w io.Writer, img image.Image, protocol Protocol, columns int) error {
+	if columns < 1 {
+		return fmt.Errorf("preview must be at least one column wide")
+	}
+	switch protocol {
+	case Kitty:
+		return renderKitty(w, img, columns)
+	case ITerm2:
+		return renderITerm2(w, img, columns)
+	case Sixel:
+		return renderSixel(w, img, columns*sixelPixelsPerColumn)
+	default:
+		return renderHalfBlock(w, img, columns)
+	}
+}
+
+func encodePNG(img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("PNG encode error: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// renderKitty transmits the image as PNG, in chunks, and displays it scaled to the columns
+func renderKitty(w io.Writer, img image.Image, columns int) error {
+	payload, err := encodePNG(img)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	for i := 0; i < len(payload); i += kittyChunkSize {
+		end := min(i+kittyChunkSize, len(payload))
+		more := 0
+		if end < len(payload) {
+			more = 1
+		}
+		if i == 0 {
+			_, _ = fmt.Fprintf(out, "\x1b_Gf=100,a=T,c=%d,m=%d;%s\x1b\\", columns, more, payload[i:end])
+		} else {
+			_, _ = fmt.Fprintf(out, "\x1b_Gm=%d;%s\x1b\\", more, payload[i:end])
+		}
+	}
+	_, _ = out.WriteString("\n")
+	return out.Flush()
+}
+
+// renderITerm2 sends the image as an inline file, scaled to the columns
+func renderITerm2(w io.Writer, img image.Image, columns int) error {
+	payload, err := encodePNG(img)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\x1b]1337;File=inline=1;size=%d;width=%d;preserveAspectRatio=1:%s\a\n",
+		base64.StdEncoding.DecodedLen(len(payload)), columns, payload)
+	return err
+}
+
+// renderHalfBlock draws two pixels in each character cell, the upper half block in the foreground color,
+// and the lower half in the background color
+func renderHalfBlock(w io.Writer, img image.Image, columns int) error {
+	scaled := scale(img, columns)
+	bounds := scaled.Bounds()
+
+	out := bufio.NewWriter(w)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			top := scaled.RGBAAt(x, y)
+			bottom := color.RGBA{}
+			if y+1 < bounds.Max.Y {
+				bottom = scaled.RGBAAt(x, y+1)
+			}
+			_, _ = fmt.Fprintf(out, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+		}
+		_, _ = out.WriteString("\x1b[0m\n")
+	}
+	return out.Flush()
+}
+
+// scale resizes the image to the width in pixels, keeping its aspect ratio, on a black background
+func scale(img image.Image, width int) *image.RGBA {
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+	width = min(width, bounds.Dx())
+	height := max(1, bounds.Dy()*width/bounds.Dx())
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(scaled, scaled.Bounds(), image.Black, image.Point{}, draw.Src)
+	draw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), img, bounds, draw.Over, nil)
+	return scaled
+}
@@ -0,0 +1,82 @@
+package preview_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/duanemay/chatgpt-cli/pkg/preview"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Preview", func() {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	newImage := func(width int, height int) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for x := 0; x < width; x++ {
+			for y := 0; y < height; y++ {
+				img.Set(x, y, color.RGBA{R: 0xff, A: 0xff})
+			}
+		}
+		return img
+	}
+
+	It("should detect the protocol from the environment", func() {
+		Ω(preview.DetectProtocol(env(map[string]string{"KITTY_WINDOW_ID": "1"}))).To(Equal(preview.Kitty))
+		Ω(preview.DetectProtocol(env(map[string]string{"TERM": "xterm-ghostty"}))).To(Equal(preview.Kitty))
+		Ω(preview.DetectProtocol(env(map[string]string{"TERM_PROGRAM": "iTerm.app"}))).To(Equal(preview.ITerm2))
+		Ω(preview.DetectProtocol(env(map[string]string{"TERM_PROGRAM": "WezTerm"}))).To(Equal(preview.ITerm2))
+		Ω(preview.DetectProtocol(env(map[string]string{"TERM": "foot-extra"}))).To(Equal(preview.Sixel))
+		Ω(preview.DetectProtocol(env(map[string]string{"TERM": "xterm-256color"}))).To(Equal(preview.HalfBlock))
+		Ω(preview.DetectProtocol(env(nil))).To(Equal(preview.HalfBlock))
+	})
+
+	It("should draw half blocks, two pixel rows per line", func() {
+		var buf bytes.Buffer
+		Ω(preview.Render(&buf, newImage(8, 8), preview.HalfBlock, 4)).To(Succeed())
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		Ω(lines).To(HaveLen(2))
+		Ω(strings.Count(lines[0], "▀")).To(Equal(4))
+		Ω(lines[0]).To(HavePrefix("\x1b[38;2;255;0;0m\x1b[48;2;255;0;0m▀"))
+		Ω(lines[0]).To(HaveSuffix("\x1b[0m"))
+	})
+
+	It("should not scale images up", func() {
+		var buf bytes.Buffer
+		Ω(preview.Render(&buf, newImage(2, 2), preview.HalfBlock, 40)).To(Succeed())
+		Ω(strings.Count(buf.String(), "▀")).To(Equal(2))
+	})
+
+	It("should send Kitty graphics in chunks", func() {
+		var buf bytes.Buffer
+		Ω(preview.Render(&buf, newImage(4, 4), preview.Kitty, 20)).To(Succeed())
+		Ω(buf.String()).To(HavePrefix("\x1b_Gf=100,a=T,c=20,m=0;"))
+		Ω(buf.String()).To(HaveSuffix("\x1b\\\n"))
+	})
+
+	It("should send an iTerm2 inline image", func() {
+		var buf bytes.Buffer
+		Ω(preview.Render(&buf, newImage(4, 4), preview.ITerm2, 20)).To(Succeed())
+		Ω(buf.String()).To(HavePrefix("\x1b]1337;File=inline=1;size="))
+		Ω(buf.String()).To(ContainSubstring(";width=20;preserveAspectRatio=1:"))
+		Ω(buf.String()).To(HaveSuffix("\a\n"))
+	})
+
+	It("should write Sixel bands", func() {
+		var buf bytes.Buffer
+		Ω(preview.Render(&buf, newImage(16, 8), preview.Sixel, 2)).To(Succeed())
+		output := buf.String()
+		// the red palette entry, then two bands, each a run of 16 sixels, of six then two rows
+		Ω(output).To(Equal("\x1bPq\"1;1;16;8#180;2;100;0;0#180!16~-#180!16B-\x1b\\\n"))
+	})
+
+	It("should require a width", func() {
+		Ω(preview.Render(&bytes.Buffer{}, newImage(4, 4), preview.HalfBlock, 0)).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,103 @@
+package preview
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"io"
+
+	"golang.org/x/image/draw"
+)
+
+// sixelBandHeight is the number of pixel rows in each line of sixels
+const sixelBandHeight = 6
+
+// renderSixel reduces the image to the web safe palette, with dithering, and writes it as Sixel graphics
+func renderSixel(w io.Writer, img image.Image, width int) error {
+	scaled := scale(img, width)
+	paletted := image.NewPaletted(scaled.Bounds(), palette.WebSafe)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), scaled, image.Point{})
+
+	out := bufio.NewWriter(w)
+	if err := writeSixel(out, paletted); err != nil {
+		return err
+	}
+	_, _ = out.WriteString("\n")
+	return out.Flush()
+}
+
+// writeSixel writes the paletted image as a Sixel sequence, one band of six rows at a time,
+// drawing the pixels of each color in the band in turn
+func writeSixel(out *bufio.Writer, img *image.Paletted) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	_, _ = fmt.Fprintf(out, "\x1bPq\"1;1;%d;%d", width, height)
+	// only the colors in the image are defined, to keep the sequence short
+	defined := make([]bool, len(img.Palette))
+	for _, index := range img.Pix {
+		if defined[index] {
+			continue
+		}
+		defined[index] = true
+		r, g, b, _ := img.Palette[index].RGBA()
+		_, _ = fmt.Fprintf(out, "#%d;2;%d;%d;%d", index, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	for band := 0; band < height; band += sixelBandHeight {
+		used := make([]bool, len(img.Palette))
+		for y := band; y < min(band+sixelBandHeight, height); y++ {
+			for x := 0; x < width; x++ {
+				used[img.ColorIndexAt(bounds.Min.X+x, bounds.Min.Y+y)] = true
+			}
+		}
+
+		first := true
+		for index, isUsed := range used {
+			if !isUsed {
+				continue
+			}
+			if !first {
+				// return to the start of the band, to overlay the next color
+				_ = out.WriteByte('$')
+			}
+			first = false
+			_, _ = fmt.Fprintf(out, "#%d", index)
+
+			var last byte
+			run := 0
+			for x := 0; x < width; x++ {
+				var bits byte
+				for dy := 0; dy < sixelBandHeight && band+dy < height; dy++ {
+					if int(img.ColorIndexAt(bounds.Min.X+x, bounds.Min.Y+band+dy)) == index {
+						bits |= 1 << dy
+					}
+				}
+				sixel := '?' + bits
+				if run > 0 && sixel != last {
+					writeSixelRun(out, last, run)
+					run = 0
+				}
+				last = sixel
+				run++
+			}
+			writeSixelRun(out, last, run)
+		}
+		_ = out.WriteByte('-')
+	}
+
+	_, err := out.WriteString("\x1b\\")
+	return err
+}
+
+// writeSixelRun writes a repeated sixel, using the repeat introducer when it is shorter
+func writeSixelRun(out *bufio.Writer, sixel byte, run int) {
+	if run > 3 {
+		_, _ = fmt.Fprintf(out, "!%d%c", run, sixel)
+		return
+	}
+	for i := 0; i < run; i++ {
+		_ = out.WriteByte(sixel)
+	}
+}